@@ -0,0 +1,119 @@
+package xreflect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	//AccessorSkip accessor tag value to skip field accessors
+	AccessorSkip = "-"
+	//AccessorReadOnly accessor tag value to generate field getter only
+	AccessorReadOnly = "readonly"
+)
+
+// accessorField represents generated struct field accessor spec
+type accessorField struct {
+	name     string
+	typeExpr string
+	readOnly bool
+}
+
+func (o *generateOption) hasAccessors() bool {
+	return o.getters || o.setters || o.presence
+}
+
+// newAccessorField returns field accessor spec or nil if field accessors are skipped
+func newAccessorField(name, typeExpr, accessor string) *accessorField {
+	if name == "" || !isExported(name) {
+		return nil
+	}
+	ret := &accessorField{name: name, typeExpr: typeExpr}
+	for _, item := range strings.Split(accessor, ",") {
+		switch strings.TrimSpace(item) {
+		case AccessorSkip:
+			return nil
+		case AccessorReadOnly:
+			ret.readOnly = true
+		}
+	}
+	return ret
+}
+
+// presenceFieldName returns presence bitmask field name not used by struct fields
+func presenceFieldName(structType reflect.Type) string {
+	ret := "has"
+	for i := 1; ; i++ {
+		if _, ok := structType.FieldByName(ret); !ok {
+			return ret
+		}
+		ret = "has" + strconv.Itoa(i)
+	}
+}
+
+// appendPresenceField writes presence bitmask field tracking which fields were set, read only fields are not tracked
+func appendPresenceField(builder *strings.Builder, fieldName string, fields []*accessorField) {
+	tracked := 0
+	for _, field := range fields {
+		if !field.readOnly {
+			tracked++
+		}
+	}
+	if tracked == 0 {
+		return
+	}
+	builder.WriteString("\n    " + fieldName + " [" + strconv.Itoa((tracked+63)/64) + "]uint64")
+}
+
+// appendAccessors writes getter, setter and has marker methods for generated struct fields, presence implies setters
+func appendAccessors(builder *strings.Builder, typeName string, presenceField string, fields []*accessorField, opts *generateOption) {
+	receiver := receiverName(typeName)
+	tracked := 0
+	for _, field := range fields {
+		mask := receiver + "." + presenceField + "[" + strconv.Itoa(tracked/64) + "]"
+		bit := "1<<" + strconv.Itoa(tracked%64)
+		presence := opts.presence && !field.readOnly
+		if presence {
+			tracked++
+		}
+		if opts.getters {
+			builder.WriteString("\n\n// Get" + field.name + " returns " + field.name + ", zero value for nil receiver\n")
+			builder.WriteString("func (" + receiver + " *" + typeName + ") Get" + field.name + "() (ret " + field.typeExpr + ") {\n")
+			builder.WriteString("if " + receiver + " == nil {\nreturn ret\n}\n")
+			builder.WriteString("return " + receiver + "." + field.name + "\n}")
+		}
+		if (opts.setters || presence) && !field.readOnly {
+			builder.WriteString("\n\n// Set" + field.name + " sets " + field.name)
+			if presence {
+				builder.WriteString(" and marks it as set")
+			}
+			builder.WriteString("\nfunc (" + receiver + " *" + typeName + ") Set" + field.name + "(value " + field.typeExpr + ") {\n")
+			builder.WriteString(receiver + "." + field.name + " = value\n")
+			if presence {
+				builder.WriteString(mask + " |= " + bit + "\n")
+			}
+			builder.WriteString("}")
+		}
+		if presence {
+			builder.WriteString("\n\n// Has" + field.name + " returns true if " + field.name + " was set\n")
+			builder.WriteString("func (" + receiver + " *" + typeName + ") Has" + field.name + "() bool {\n")
+			builder.WriteString("return " + receiver + " != nil && " + mask + "&(" + bit + ") != 0\n}")
+		}
+	}
+}
+
+func receiverName(typeName string) string {
+	for _, r := range typeName {
+		return string(unicode.ToLower(r))
+	}
+	return "t"
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
@@ -0,0 +1,381 @@
+package xreflect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// cacheVersion changes whenever descriptor format changes
+const cacheVersion = "xreflect.v1"
+
+type (
+	//packageDescriptor represents cached package types, methods, constants and imports
+	packageDescriptor struct {
+		Name       string              `json:"name"`
+		ModulePath string              `json:"modulePath,omitempty"`
+		Types      []*typeEntry        `json:"types,omitempty"`
+		Constants  map[string]string   `json:"constants,omitempty"`
+		Imports    map[string][]string `json:"imports,omitempty"`
+		//Dependencies holds source key of every registry package the cached types were resolved with, keyed by import path
+		Dependencies map[string]string `json:"dependencies,omitempty"`
+	}
+
+	typeEntry struct {
+		Name    string          `json:"name"`
+		Type    *typeDescriptor `json:"type"`
+		Methods []string        `json:"methods,omitempty"`
+	}
+
+	//typeDescriptor represents serializable reflect.Type
+	typeDescriptor struct {
+		Kind    string             `json:"kind"`
+		Name    string             `json:"name,omitempty"`
+		PkgPath string             `json:"pkgPath,omitempty"`
+		Len     int                `json:"len,omitempty"`
+		Key     *typeDescriptor    `json:"key,omitempty"`
+		Elem    *typeDescriptor    `json:"elem,omitempty"`
+		Fields  []*fieldDescriptor `json:"fields,omitempty"`
+	}
+
+	fieldDescriptor struct {
+		Name      string          `json:"name"`
+		PkgPath   string          `json:"pkgPath,omitempty"`
+		Tag       string          `json:"tag,omitempty"`
+		Anonymous bool            `json:"anonymous,omitempty"`
+		Type      *typeDescriptor `json:"type"`
+	}
+)
+
+// cacheKey returns hash of descriptor format, parse and registry options and location source files, or empty string if location can not be read
+func (t *Types) cacheKey(location string, parseOptions []Option) string {
+	source := sourceKey(location)
+	if source == "" {
+		return ""
+	}
+	o := &options{}
+	o.Apply(parseOptions...)
+	hash := sha256.New()
+	hash.Write([]byte(cacheVersion))
+	fmt.Fprintf(hash, "mode=%d;breakCycles=%v;lenient=%v;policy=%v;", o.parseMode, o.breakCycles, o.lenient, t.policy)
+	hash.Write([]byte(source))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// sourceKey returns hash of location non test source files, or empty string if location can not be read
+func sourceKey(location string) string {
+	entries, err := os.ReadDir(location)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	hash.Write([]byte(location))
+	for _, entry := range entries { //entries are sorted by name
+		name := entry.Name()
+		if !isSourceFile(entry) {
+			continue
+		}
+		data, err := os.ReadFile(path.Join(location, name))
+		if err != nil {
+			return ""
+		}
+		hash.Write([]byte(name))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isSourceFile(entry os.DirEntry) bool {
+	name := entry.Name()
+	return !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// dependencyKeys returns source keys of registry packages transitively imported by supplied imports, packages without source location are skipped
+func (t *Types) dependencyKeys(imports []string) (map[string]string, error) {
+	result := map[string]string{}
+	for len(imports) > 0 {
+		importPath := imports[0]
+		imports = imports[1:]
+		if _, ok := result[importPath]; ok {
+			continue
+		}
+		pkg := t.dependencyPackage(importPath)
+		if pkg == nil || pkg.Path == "" {
+			continue
+		}
+		key := sourceKey(pkg.Path)
+		if key == "" {
+			return nil, fmt.Errorf("failed to read dependency %v source: %v", importPath, pkg.Path)
+		}
+		result[importPath] = key
+		pkgImports, err := sourceImports(pkg.Path)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, pkgImports...)
+	}
+	return result, nil
+}
+
+// dependencyPackage returns package registered under import path in registry or its parents
+func (t *Types) dependencyPackage(importPath string) *Package {
+	for registry := t; registry != nil; registry = registry.parent {
+		if pkg := registry.packageMap()[importPath]; pkg != nil {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// sourceImports returns import paths of location non test source files
+func sourceImports(location string) ([]string, error) {
+	packages, err := parser.ParseDir(token.NewFileSet(), location, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, spec := range file.Imports {
+				if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+					result = append(result, importPath)
+				}
+			}
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// validDependencies returns true if cached dependency sources did not change
+func (t *Types) validDependencies(dependencies map[string]string) bool {
+	for importPath, key := range dependencies {
+		pkg := t.dependencyPackage(importPath)
+		if pkg == nil || pkg.Path == "" || sourceKey(pkg.Path) != key {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Types) cacheFile(key string) string {
+	return path.Join(t.cacheDir, key+".json")
+}
+
+// loadCache registers cached package types, returns package and matched type or nil if type was not cached
+func (t *Types) loadCache(key string, aType *Type, pkg *Package) (*Package, reflect.Type) {
+	data, err := os.ReadFile(t.cacheFile(key))
+	if err != nil {
+		return nil, nil
+	}
+	descriptor := &packageDescriptor{}
+	if err = json.Unmarshal(data, descriptor); err != nil || !t.validDependencies(descriptor.Dependencies) {
+		return nil, nil
+	}
+	name := rawName(aType.Name)
+	types := map[string]reflect.Type{}
+	var matched reflect.Type
+	for _, entry := range descriptor.Types {
+		rType, err := entry.Type.reflectType(t)
+		if err != nil {
+			return nil, nil
+		}
+		types[entry.Name] = rType
+		if entry.Name == name {
+			matched = rType
+		}
+	}
+	if matched == nil {
+		return nil, nil
+	}
+	pkgName := descriptor.Name
+	if pkgName == "" {
+		pkgName = pkg.Name
+	}
+	if pkg, err = t.importPackage(descriptor.ModulePath, pkgName, aType.PackagePath); err != nil {
+		return nil, nil
+	}
+	for _, entry := range descriptor.Types {
+		if previous, changed, err := pkg.register(entry.Name, types[entry.Name], t.policy); err == nil && changed {
+			t.notifyChange(pkg.Name, entry.Name, previous, types[entry.Name])
+		}
+		var methods []reflect.Method
+		for _, method := range entry.Methods {
+			methods = append(methods, newMethod(method))
+		}
+		pkg.mux.Lock()
+		pkg.methods[entry.Name] = methods
+		pkg.mux.Unlock()
+	}
+	atomic.AddUint64(&t.generation, 1)
+	pkg.mux.Lock()
+	pkg.constants = descriptor.Constants
+	pkg.imports = descriptor.Imports
+	aType.Methods = append(aType.Methods, pkg.methods[name]...)
+	pkg.mux.Unlock()
+	aType.ModulePath = descriptor.ModulePath
+	return pkg, matched
+}
+
+// storeCache resolves all package types and writes package descriptor to the cache directory,
+// package is not cached if any type can not be resolved or described
+func (t *Types) storeCache(key string, pkgName string, dirType *DirTypes) error {
+	descriptor := &packageDescriptor{Name: pkgName, ModulePath: dirType.ModulePath, Constants: map[string]string{}, Imports: map[string][]string{}}
+	names := dirType.TypesNames()
+	sort.Strings(names)
+	for _, name := range names {
+		rType, err := dirType.Type(name)
+		if err != nil {
+			return fmt.Errorf("failed to cache %v: %w", name, err)
+		}
+		typeDescriptor, err := describeType(rType)
+		if err != nil {
+			return fmt.Errorf("failed to cache %v: %w", name, err)
+		}
+		entry := &typeEntry{Name: name, Type: typeDescriptor}
+		for _, method := range dirType.Methods(name) {
+			entry.Methods = append(entry.Methods, AsMethod(method).Name)
+		}
+		descriptor.Types = append(descriptor.Types, entry)
+	}
+	for _, scope := range dirType.scopes {
+		for name := range scope.Objects {
+			if value, err := dirType.Value(name); err == nil {
+				if literal, ok := value.(*ast.BasicLit); ok {
+					descriptor.Constants[name] = literal.Value
+				}
+			}
+		}
+	}
+	var imports []string
+	for file := range dirType.imports {
+		descriptor.Imports[path.Base(file)] = dirType.Imports(file)
+		imports = append(imports, dirType.Imports(file)...)
+	}
+	dependencies, err := t.dependencyKeys(imports)
+	if err != nil {
+		return err
+	}
+	descriptor.Dependencies = dependencies
+	data, err := json.Marshal(descriptor)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(t.cacheDir, 0755); err != nil {
+		return err
+	}
+	tempFile := t.cacheFile(key) + ".tmp"
+	if err = os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, t.cacheFile(key))
+}
+
+func describeType(rType reflect.Type) (*typeDescriptor, error) {
+	ret := &typeDescriptor{Kind: rType.Kind().String()}
+	if rType.Name() != "" {
+		ret.Name = rType.Name()
+		ret.PkgPath = rType.PkgPath()
+		return ret, nil
+	}
+	var err error
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		ret.Elem, err = describeType(rType.Elem())
+	case reflect.Array:
+		ret.Len = rType.Len()
+		ret.Elem, err = describeType(rType.Elem())
+	case reflect.Map:
+		if ret.Key, err = describeType(rType.Key()); err == nil {
+			ret.Elem, err = describeType(rType.Elem())
+		}
+	case reflect.Interface:
+	case reflect.Struct:
+		for i := 0; i < rType.NumField(); i++ {
+			field := rType.Field(i)
+			fieldType, err := describeType(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			ret.Fields = append(ret.Fields, &fieldDescriptor{Name: field.Name, PkgPath: field.PkgPath, Tag: string(field.Tag), Anonymous: field.Anonymous, Type: fieldType})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind: %v", rType.Kind())
+	}
+	return ret, err
+}
+
+func (d *typeDescriptor) reflectType(registry *Types) (reflect.Type, error) {
+	if d.Name != "" {
+		return d.namedType(registry)
+	}
+	switch d.Kind {
+	case reflect.Ptr.String(), reflect.Slice.String(), reflect.Array.String():
+		elem, err := d.Elem.reflectType(registry)
+		if err != nil {
+			return nil, err
+		}
+		switch d.Kind {
+		case reflect.Ptr.String():
+			return reflect.PtrTo(elem), nil
+		case reflect.Array.String():
+			return reflect.ArrayOf(d.Len, elem), nil
+		}
+		return reflect.SliceOf(elem), nil
+	case reflect.Map.String():
+		key, err := d.Key.reflectType(registry)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := d.Elem.reflectType(registry)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(key, elem), nil
+	case reflect.Interface.String():
+		return InterfaceType, nil
+	case reflect.Struct.String():
+		fields := make([]reflect.StructField, 0, len(d.Fields))
+		for _, field := range d.Fields {
+			fieldType, err := field.Type.reflectType(registry)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, reflect.StructField{Name: field.Name, PkgPath: field.PkgPath, Tag: reflect.StructTag(field.Tag), Anonymous: field.Anonymous, Type: fieldType})
+		}
+		return reflect.StructOf(fields), nil
+	}
+	return nil, fmt.Errorf("unsupported kind: %v", d.Kind)
+}
+
+func (d *typeDescriptor) namedType(registry *Types) (reflect.Type, error) {
+	switch d.PkgPath {
+	case "":
+		if d.Name == "error" {
+			return ErrorType, nil
+		}
+		return buildInTypes.lookupPackage("").Lookup(d.Name)
+	case TimeType.PkgPath():
+		if d.Name == TimeType.Name() {
+			return TimeType, nil
+		}
+	case JSONRawMessageType.PkgPath():
+		if d.Name == JSONRawMessageType.Name() {
+			return JSONRawMessageType, nil
+		}
+	}
+	return registry.Lookup(d.Name, WithImportPath(d.PkgPath))
+}
@@ -0,0 +1,400 @@
+package xreflect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeepCopy returns deep copy of value, pointers, slices, maps, interface values and exported struct fields are copied recursively,
+// nil slices and maps stay nil, unexported fields are copied shallowly like generated DeepCopyInto,
+// shared and cyclic pointers and maps are copied once
+func DeepCopy(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	src := reflect.ValueOf(value)
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src, map[visitedKey]reflect.Value{})
+	return dst.Interface()
+}
+
+// visitedKey represents copied pointer or map, type is part of the key as struct and its first field share address
+type visitedKey struct {
+	pointer uintptr
+	rType   reflect.Type
+}
+
+func deepCopy(dst, src reflect.Value, visited map[visitedKey]reflect.Value) {
+	dst.Set(src)
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := visitedKey{pointer: src.Pointer(), rType: src.Type()}
+		if ptr, ok := visited[key]; ok {
+			dst.Set(ptr)
+			return
+		}
+		ptr := reflect.New(src.Type().Elem())
+		visited[key] = ptr
+		deepCopy(ptr.Elem(), src.Elem(), visited)
+		dst.Set(ptr)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		item := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(item, src.Elem(), visited)
+		dst.Set(item)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		slice := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(slice.Index(i), src.Index(i), visited)
+		}
+		dst.Set(slice)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i), visited)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := visitedKey{pointer: src.Pointer(), rType: src.Type()}
+		if aMap, ok := visited[key]; ok {
+			dst.Set(aMap)
+			return
+		}
+		aMap := reflect.MakeMapWithSize(src.Type(), src.Len())
+		visited[key] = aMap
+		iter := src.MapRange()
+		for iter.Next() {
+			item := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(item, iter.Value(), visited)
+			aMap.SetMapIndex(iter.Key(), item)
+		}
+		dst.Set(aMap)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).PkgPath == "" {
+				deepCopy(dst.Field(i), src.Field(i), visited)
+			}
+		}
+	}
+}
+
+// DeepEqual returns true if values are deeply equal, time.Time is compared with Equal, nil and empty slices and maps are equal,
+// unexported and func fields are ignored like in generated Equal, cyclic pointers and maps are compared once
+func DeepEqual(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	xValue, yValue := reflect.ValueOf(x), reflect.ValueOf(y)
+	if xValue.Type() != yValue.Type() {
+		return false
+	}
+	return deepEqual(xValue, yValue, map[visitedPair]bool{})
+}
+
+// visitedPair represents compared pointers, maps or slices, comparison in progress is assumed equal like in reflect.DeepEqual
+type visitedPair struct {
+	x, y  uintptr
+	rType reflect.Type
+}
+
+// visit returns true if x and y were already compared
+func visit(x, y reflect.Value, visited map[visitedPair]bool) bool {
+	key := visitedPair{x: x.Pointer(), y: y.Pointer(), rType: x.Type()}
+	if visited[key] {
+		return true
+	}
+	visited[key] = true
+	return false
+}
+
+func deepEqual(x, y reflect.Value, visited map[visitedPair]bool) bool {
+	if x.Type() == TimeType {
+		return x.Interface().(time.Time).Equal(y.Interface().(time.Time))
+	}
+	switch x.Kind() {
+	case reflect.Ptr:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		if visit(x, y, visited) {
+			return true
+		}
+		return deepEqual(x.Elem(), y.Elem(), visited)
+	case reflect.Slice, reflect.Array:
+		if x.Len() != y.Len() {
+			return false
+		}
+		if x.Kind() == reflect.Slice && x.Len() > 0 && visit(x, y, visited) {
+			return true
+		}
+		for i := 0; i < x.Len(); i++ {
+			if !deepEqual(x.Index(i), y.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if x.Len() != y.Len() {
+			return false
+		}
+		if x.Len() > 0 && visit(x, y, visited) {
+			return true
+		}
+		iter := x.MapRange()
+		for iter.Next() {
+			item := y.MapIndex(iter.Key())
+			if !item.IsValid() || !deepEqual(iter.Value(), item, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < x.NumField(); i++ {
+			if x.Type().Field(i).PkgPath == "" && !deepEqual(x.Field(i), y.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		return reflect.DeepEqual(x.Interface(), y.Interface())
+	case reflect.Func:
+		return true
+	default:
+		return x.Interface() == y.Interface()
+	}
+}
+
+// methodField represents generated struct field used by DeepCopyInto and Equal
+type methodField struct {
+	name  string
+	rType reflect.Type
+	types *fieldTypes
+}
+
+func newMethodField(aField *reflect.StructField, types *fieldTypes) *methodField {
+	if aField.PkgPath != "" {
+		return nil
+	}
+	return &methodField{name: aField.Name, rType: aField.Type, types: types}
+}
+
+// needsCopy returns true if type value shares memory after assignment
+func (f *methodField) needsCopy(rType reflect.Type) bool {
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Array:
+		return f.needsCopy(rType.Elem())
+	case reflect.Struct:
+		return f.types.generated(rType)
+	}
+	return false
+}
+
+// appendDeepCopy writes statements deep copying src into dst, dst holds shallow copy of src,
+// generated types share visited copies, interface dynamic values are copied with DeepCopy
+func (f *methodField) appendDeepCopy(builder *strings.Builder, dst, src string, rType reflect.Type, depth int) {
+	suffix := strconv.Itoa(depth)
+	if f.types.generated(rType) {
+		builder.WriteString(src + ".deepCopyInto(&" + dst + ", visited)\n")
+		return
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		builder.WriteString("if " + src + " != nil {\n")
+		if f.types.generated(rType.Elem()) {
+			builder.WriteString(dst + " = " + src + ".clone(visited)\n}\n")
+			return
+		}
+		builder.WriteString(dst + " = new(" + f.types.expr(rType.Elem()) + ")\n")
+		builder.WriteString("*" + dst + " = *" + src + "\n")
+		if f.needsCopy(rType.Elem()) {
+			f.appendDeepCopy(builder, "(*"+dst+")", "(*"+src+")", rType.Elem(), depth+1)
+		}
+		builder.WriteString("}\n")
+	case reflect.Slice:
+		builder.WriteString("if " + src + " != nil {\n")
+		builder.WriteString(dst + " = make(" + f.types.expr(rType) + ", len(" + src + "))\n")
+		builder.WriteString("copy(" + dst + ", " + src + ")\n")
+		f.appendElementsCopy(builder, dst, src, rType, suffix, depth)
+		builder.WriteString("}\n")
+	case reflect.Array:
+		f.appendElementsCopy(builder, dst, src, rType, suffix, depth)
+	case reflect.Interface:
+		builder.WriteString("if " + src + " != nil {\n")
+		builder.WriteString(dst + " = " + f.types.qualify(xreflectImportPath, "xreflect") + ".DeepCopy(" + src + ")")
+		if rType.NumMethod() > 0 {
+			builder.WriteString(".(" + f.types.expr(rType) + ")")
+		}
+		builder.WriteString("\n}\n")
+	case reflect.Map:
+		builder.WriteString("if " + src + " != nil {\n")
+		builder.WriteString(dst + " = make(" + f.types.expr(rType) + ", len(" + src + "))\n")
+		key, value := "k"+suffix, "v"+suffix
+		builder.WriteString("for " + key + ", " + value + " := range " + src + " {\n")
+		if f.needsCopy(rType.Elem()) {
+			item := "c" + suffix
+			builder.WriteString(item + " := " + value + "\n")
+			f.appendDeepCopy(builder, item, value, rType.Elem(), depth+1)
+			value = item
+		}
+		builder.WriteString(dst + "[" + key + "] = " + value + "\n}\n}\n")
+	}
+}
+
+func (f *methodField) appendElementsCopy(builder *strings.Builder, dst, src string, rType reflect.Type, suffix string, depth int) {
+	if !f.needsCopy(rType.Elem()) {
+		return
+	}
+	index := "i" + suffix
+	builder.WriteString("for " + index + " := range " + src + " {\n")
+	f.appendDeepCopy(builder, dst+"["+index+"]", src+"["+index+"]", rType.Elem(), depth+1)
+	builder.WriteString("}\n")
+}
+
+// needsEqual returns true if == comparison differs from DeepEqual
+func needsEqual(rType reflect.Type) bool {
+	if rType == TimeType {
+		return true
+	}
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func:
+		return true
+	case reflect.Array:
+		return needsEqual(rType.Elem())
+	case reflect.Struct:
+		for i := 0; i < rType.NumField(); i++ {
+			if aField := rType.Field(i); aField.PkgPath == "" && needsEqual(aField.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refersTo returns true if type exported fields or elements refer to target type
+func refersTo(rType, target reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[rType] {
+		return false
+	}
+	visited[rType] = true
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return rType.Elem() == target || refersTo(rType.Elem(), target, visited)
+	case reflect.Struct:
+		for i := 0; i < rType.NumField(); i++ {
+			if aField := rType.Field(i); aField.PkgPath == "" && (aField.Type == target || refersTo(aField.Type, target, visited)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendEqual writes statements returning false if x and y differ, generated types share visited pairs
+func (f *methodField) appendEqual(builder *strings.Builder, x, y string, rType reflect.Type, depth int) {
+	suffix := strconv.Itoa(depth)
+	if f.types.generated(rType) {
+		builder.WriteString("if !" + x + ".equal(&" + y + ", visited) {\nreturn false\n}\n")
+		return
+	}
+	if rType == TimeType {
+		builder.WriteString("if !" + x + ".Equal(" + y + ") {\nreturn false\n}\n")
+		return
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		if f.types.generated(rType.Elem()) {
+			builder.WriteString("if !" + x + ".equal(" + y + ", visited) {\nreturn false\n}\n")
+			return
+		}
+		builder.WriteString("if (" + x + " == nil) != (" + y + " == nil) {\nreturn false\n}\n")
+		builder.WriteString("if " + x + " != nil {\n")
+		f.appendEqual(builder, "(*"+x+")", "(*"+y+")", rType.Elem(), depth+1)
+		builder.WriteString("}\n")
+	case reflect.Slice, reflect.Array:
+		if rType.Kind() == reflect.Slice {
+			builder.WriteString("if len(" + x + ") != len(" + y + ") {\nreturn false\n}\n")
+		}
+		index := "i" + suffix
+		builder.WriteString("for " + index + " := range " + x + " {\n")
+		f.appendEqual(builder, x+"["+index+"]", y+"["+index+"]", rType.Elem(), depth+1)
+		builder.WriteString("}\n")
+	case reflect.Map:
+		key, value, other := "k"+suffix, "v"+suffix, "w"+suffix
+		builder.WriteString("if len(" + x + ") != len(" + y + ") {\nreturn false\n}\n")
+		builder.WriteString("for " + key + ", " + value + " := range " + x + " {\n")
+		builder.WriteString(other + ", ok := " + y + "[" + key + "]\n")
+		builder.WriteString("if !ok {\nreturn false\n}\n")
+		f.appendEqual(builder, value, other, rType.Elem(), depth+1)
+		builder.WriteString("}\n")
+	case reflect.Func:
+	case reflect.Struct:
+		if rType.Comparable() && !needsEqual(rType) {
+			builder.WriteString("if " + x + " != " + y + " {\nreturn false\n}\n")
+			return
+		}
+		if refersTo(rType, rType, map[reflect.Type]bool{}) { //recursive type can not be expanded
+			builder.WriteString("if !" + f.types.qualify(xreflectImportPath, "xreflect") + ".DeepEqual(" + x + ", " + y + ") {\nreturn false\n}\n")
+			return
+		}
+		for i := 0; i < rType.NumField(); i++ {
+			if aField := rType.Field(i); aField.PkgPath == "" {
+				f.appendEqual(builder, x+"."+aField.Name, y+"."+aField.Name, aField.Type, depth+1)
+			}
+		}
+	case reflect.Interface:
+		builder.WriteString("if !" + f.types.qualify("reflect", "reflect") + ".DeepEqual(" + x + ", " + y + ") {\nreturn false\n}\n")
+	default:
+		builder.WriteString("if " + x + " != " + y + " {\nreturn false\n}\n")
+	}
+}
+
+// appendDeepCopyMethods writes Clone and DeepCopyInto methods, visited maps source pointers to copies so shared and cyclic pointers are copied once
+func appendDeepCopyMethods(builder *strings.Builder, typeName string, fields []*methodField) {
+	receiver := receiverName(typeName)
+	builder.WriteString("\n\n// Clone returns deep copy of " + typeName + "\n")
+	builder.WriteString("func (" + receiver + " *" + typeName + ") Clone() *" + typeName + " {\n")
+	builder.WriteString("return " + receiver + ".clone(map[interface{}]interface{}{})\n}")
+	builder.WriteString("\n\n// DeepCopyInto deep copies " + typeName + " into dest\n")
+	builder.WriteString("func (" + receiver + " *" + typeName + ") DeepCopyInto(dest *" + typeName + ") {\n")
+	builder.WriteString(receiver + ".deepCopyInto(dest, map[interface{}]interface{}{" + receiver + ": dest})\n}")
+	builder.WriteString("\n\nfunc (" + receiver + " *" + typeName + ") clone(visited map[interface{}]interface{}) *" + typeName + " {\n")
+	builder.WriteString("if " + receiver + " == nil {\nreturn nil\n}\n")
+	builder.WriteString("if ret, ok := visited[" + receiver + "]; ok {\nreturn ret.(*" + typeName + ")\n}\n")
+	builder.WriteString("ret := &" + typeName + "{}\nvisited[" + receiver + "] = ret\n" + receiver + ".deepCopyInto(ret, visited)\nreturn ret\n}")
+	builder.WriteString("\n\nfunc (" + receiver + " *" + typeName + ") deepCopyInto(dest *" + typeName + ", visited map[interface{}]interface{}) {\n")
+	builder.WriteString("*dest = *" + receiver + "\n")
+	for _, field := range fields {
+		if field.needsCopy(field.rType) {
+			field.appendDeepCopy(builder, "dest."+field.name, receiver+"."+field.name, field.rType, 0)
+		}
+	}
+	builder.WriteString("}")
+}
+
+// appendEqualMethod writes Equal method, visited holds compared pointer pairs so cyclic pointers are compared once
+func appendEqualMethod(builder *strings.Builder, typeName string, fields []*methodField) {
+	receiver := receiverName(typeName)
+	builder.WriteString("\n\n// Equal returns true if " + typeName + " is deeply equal to other\n")
+	builder.WriteString("func (" + receiver + " *" + typeName + ") Equal(other *" + typeName + ") bool {\n")
+	builder.WriteString("return " + receiver + ".equal(other, map[[2]interface{}]bool{})\n}")
+	builder.WriteString("\n\nfunc (" + receiver + " *" + typeName + ") equal(other *" + typeName + ", visited map[[2]interface{}]bool) bool {\n")
+	builder.WriteString("if " + receiver + " == nil || other == nil {\nreturn " + receiver + " == other\n}\n")
+	builder.WriteString("key := [2]interface{}{" + receiver + ", other}\nif visited[key] {\nreturn true\n}\nvisited[key] = true\n")
+	for _, field := range fields {
+		field.appendEqual(builder, receiver+"."+field.name, "other."+field.name, field.rType, 0)
+	}
+	builder.WriteString("return true\n}")
+}
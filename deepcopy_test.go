@@ -0,0 +1,133 @@
+package xreflect
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/xreflect/internal/testdata"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type deepCopyItem struct {
+	Name   string
+	Values map[string]*int
+}
+
+type deepCopyFoo struct {
+	ID      int
+	Tags    []string
+	Ratio   *float64
+	Created time.Time
+	Raw     []byte
+	Items   []*deepCopyItem
+	ByName  map[string]deepCopyItem
+	Grid    [2][]int
+	Any     interface{}
+	secret  []int
+}
+
+func TestDeepCopy(t *testing.T) {
+	ratio, one := 1.5, 1
+	foo := &deepCopyFoo{
+		ID:      1,
+		Tags:    []string{"a"},
+		Ratio:   &ratio,
+		Created: time.Now(),
+		Raw:     []byte(`{}`),
+		Items:   []*deepCopyItem{{Name: "x", Values: map[string]*int{"one": &one}}, nil},
+		ByName:  map[string]deepCopyItem{"x": {Values: map[string]*int{"one": &one}}},
+		Grid:    [2][]int{{1}, nil},
+		Any:     []int{1},
+		secret:  []int{1},
+	}
+	clone := DeepCopy(foo).(*deepCopyFoo)
+	assert.True(t, DeepEqual(foo, clone))
+	assert.Nil(t, clone.Grid[1])
+	assert.Nil(t, clone.Items[1])
+
+	clone.Tags[0] = "b"
+	*clone.Ratio = 2
+	clone.Raw[0] = '['
+	*clone.Items[0].Values["one"] = 2
+	*clone.ByName["x"].Values["one"] = 3
+	clone.Grid[0][0] = 2
+	clone.Any.([]int)[0] = 2
+	clone.secret[0] = 2 //unexported fields are copied shallowly
+	assert.Equal(t, "a", foo.Tags[0])
+	assert.Equal(t, 1.5, *foo.Ratio)
+	assert.Equal(t, byte('{'), foo.Raw[0])
+	assert.Equal(t, 1, one)
+	assert.Equal(t, 1, foo.Grid[0][0])
+	assert.Equal(t, []int{1}, foo.Any)
+	assert.Equal(t, 2, foo.secret[0])
+	assert.False(t, DeepEqual(foo, clone))
+	assert.Nil(t, DeepCopy(nil))
+}
+
+func TestDeepCopy_Cycle(t *testing.T) {
+	parent := &testdata.Parent{Name: "parent"}
+	parent.Child = &testdata.Child{Name: "child", Parent: parent}
+	clonedParent := DeepCopy(parent).(*testdata.Parent)
+	assert.True(t, clonedParent != parent)
+	assert.True(t, clonedParent.Child != parent.Child)
+	assert.True(t, clonedParent.Child.Parent == clonedParent)
+	assert.Equal(t, "child", clonedParent.Child.Name)
+
+	child := &testdata.Node{ID: 2}
+	root := &testdata.Node{ID: 1, Children: []*testdata.Node{child, child}}
+	root.Index = map[string]*testdata.Node{"root": root, "child": child}
+	clonedRoot := DeepCopy(root).(*testdata.Node)
+	assert.True(t, clonedRoot.Children[0] != child)
+	assert.True(t, clonedRoot.Children[0] == clonedRoot.Children[1])
+	assert.True(t, clonedRoot.Index["root"] == clonedRoot)
+	assert.True(t, clonedRoot.Index["child"] == clonedRoot.Children[0])
+	assert.True(t, DeepEqual(parent, clonedParent))
+	assert.True(t, DeepEqual(root, clonedRoot))
+	clonedRoot.Index["root"].Children[1].ID = 3
+	assert.False(t, DeepEqual(root, clonedRoot))
+}
+
+func TestDeepEqual(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		description string
+		x           interface{}
+		y           interface{}
+		expect      bool
+	}{
+		{description: "time instant", x: &deepCopyFoo{Created: now}, y: &deepCopyFoo{Created: now.UTC()}, expect: true},
+		{description: "nil and empty slice", x: &deepCopyFoo{Tags: nil}, y: &deepCopyFoo{Tags: []string{}}, expect: true},
+		{description: "nil and empty map", x: &deepCopyFoo{ByName: nil}, y: &deepCopyFoo{ByName: map[string]deepCopyItem{}}, expect: true},
+		{description: "unexported ignored", x: &deepCopyFoo{secret: []int{1}}, y: &deepCopyFoo{}, expect: true},
+		{description: "nil pointer", x: &deepCopyFoo{Items: []*deepCopyItem{nil}}, y: &deepCopyFoo{Items: []*deepCopyItem{{}}}, expect: false},
+		{description: "map key", x: map[string]int{"a": 0}, y: map[string]int{"b": 0}, expect: false},
+		{description: "interface", x: &deepCopyFoo{Any: []int{1}}, y: &deepCopyFoo{Any: []int{1}}, expect: true},
+		{description: "different types", x: 1, y: int64(1), expect: false},
+		{description: "nil", x: nil, y: nil, expect: true},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expect, DeepEqual(testCase.x, testCase.y), testCase.description)
+	}
+}
+
+func TestDeepCopy_DynamicType(t *testing.T) {
+	types := NewTypes()
+	err := types.Register("Foo", WithPackage("model"), WithTypeDefinition("struct{Tags []string; Audit *struct{By string; }; }"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	rType, err := types.Lookup("Foo", WithPackage("model"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	value := reflect.New(rType).Elem()
+	value.Field(0).Set(reflect.ValueOf([]string{"a"}))
+	audit := reflect.New(rType.Field(1).Type.Elem())
+	audit.Elem().Field(0).SetString("system")
+	value.Field(1).Set(audit)
+
+	clone := reflect.ValueOf(DeepCopy(value.Interface()))
+	assert.True(t, DeepEqual(value.Interface(), clone.Interface()))
+	clone.Field(1).Elem().Field(0).SetString("user")
+	assert.Equal(t, "system", value.Field(1).Elem().Field(0).String())
+}
@@ -0,0 +1,197 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyDefaults sets zero value fields of a struct pointer to their default tag values, nested structs and non nil struct pointers are initialised recursively
+func ApplyDefaults(value interface{}) error {
+	rValue := reflect.ValueOf(value)
+	if rValue.Kind() != reflect.Ptr || rValue.IsNil() || rValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid defaults target: expected non nil struct pointer, but had %T", value)
+	}
+	return applyDefaults(rValue.Elem())
+}
+
+func applyDefaults(rValue reflect.Value) error {
+	rType := rValue.Type()
+	for i := 0; i < rType.NumField(); i++ {
+		aField := rType.Field(i)
+		if aField.PkgPath != "" { //unexported
+			continue
+		}
+		fieldValue := rValue.Field(i)
+		if literal, ok := aField.Tag.Lookup(TagDefault); ok {
+			if !fieldValue.IsZero() {
+				continue
+			}
+			defaultValue, err := parseDefault(aField.Type, literal)
+			if err != nil {
+				return fmt.Errorf("invalid %v default: %w", aField.Name, err)
+			}
+			fieldValue.Set(defaultValue)
+			continue
+		}
+		switch aField.Type.Kind() {
+		case reflect.Struct:
+			if err := applyDefaults(fieldValue); err != nil {
+				return err
+			}
+		case reflect.Ptr:
+			if aField.Type.Elem().Kind() == reflect.Struct && !fieldValue.IsNil() {
+				if err := applyDefaults(fieldValue.Elem()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseDefault returns default tag value for supplied type, pointer to basic type is allocated
+func parseDefault(rType reflect.Type, literal string) (reflect.Value, error) {
+	if rType.Kind() == reflect.Ptr {
+		elem, err := parseDefault(rType.Elem(), literal)
+		if err != nil {
+			return elem, err
+		}
+		ptr := reflect.New(rType.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+	ret := reflect.New(rType).Elem()
+	switch rType.Kind() {
+	case reflect.String:
+		ret.SetString(literal)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(literal)
+		if err != nil {
+			return ret, err
+		}
+		ret.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rType == durationType {
+			value, err := time.ParseDuration(literal)
+			if err != nil {
+				return ret, err
+			}
+			ret.SetInt(int64(value))
+			break
+		}
+		value, err := strconv.ParseInt(literal, 10, rType.Bits())
+		if err != nil {
+			return ret, err
+		}
+		ret.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(literal, 10, rType.Bits())
+		if err != nil {
+			return ret, err
+		}
+		ret.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(literal, rType.Bits())
+		if err != nil {
+			return ret, err
+		}
+		ret.SetFloat(value)
+	default:
+		return ret, fmt.Errorf("unsupported default type: %v", rType.String())
+	}
+	return ret, nil
+}
+
+// defaultLiteral returns Go literal of parsed default value
+func defaultLiteral(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return strconv.Quote(value.String())
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	default:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits())
+	}
+}
+
+func zeroLiteral(rType reflect.Type) string {
+	switch rType.Kind() {
+	case reflect.String:
+		return `""`
+	default:
+		return "0"
+	}
+}
+
+// initField represents generated Init field statement spec
+type initField struct {
+	name     string
+	typeExpr string
+	rType    reflect.Type
+	literal  string
+	nested   bool
+}
+
+// newInitField returns Init statement spec for field default tag or nested generated struct,
+// invalid defaults are reported by validateDefault and skipped by GenerateStruct
+func newInitField(aField *reflect.StructField, typeExpr string, nested bool) *initField {
+	if aField.PkgPath != "" {
+		return nil
+	}
+	ret := &initField{name: aField.Name, typeExpr: typeExpr, rType: aField.Type}
+	if literal, ok := aField.Tag.Lookup(TagDefault); ok {
+		value, err := parseDefault(aField.Type, literal)
+		if err != nil {
+			return nil
+		}
+		if value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+		ret.literal = defaultLiteral(value)
+		return ret
+	}
+	if !nested {
+		return nil
+	}
+	ret.nested = true
+	return ret
+}
+
+// appendConstructor writes NewX constructor and Init method applying field defaults
+func appendConstructor(builder *strings.Builder, typeName string, fields []*initField) {
+	receiver := receiverName(typeName)
+	builder.WriteString("\n\n// New" + typeName + " returns " + typeName + " with defaults applied\n")
+	builder.WriteString("func New" + typeName + "() *" + typeName + " {\n")
+	builder.WriteString("ret := &" + typeName + "{}\nret.Init()\nreturn ret\n}")
+	builder.WriteString("\n\n// Init sets zero value fields to their defaults\n")
+	builder.WriteString("func (" + receiver + " *" + typeName + ") Init() {\n")
+	for _, field := range fields {
+		selector := receiver + "." + field.name
+		switch {
+		case field.nested && field.rType.Kind() == reflect.Ptr:
+			builder.WriteString("if " + selector + " != nil {\n" + selector + ".Init()\n}\n")
+		case field.nested:
+			builder.WriteString(selector + ".Init()\n")
+		case field.rType.Kind() == reflect.Ptr:
+			builder.WriteString("if " + selector + " == nil {\n")
+			builder.WriteString("value := " + strings.TrimPrefix(field.typeExpr, "*") + "(" + field.literal + ")\n")
+			builder.WriteString(selector + " = &value\n}\n")
+		case field.rType.Kind() == reflect.Bool:
+			builder.WriteString("if !" + selector + " {\n")
+			builder.WriteString(selector + " = " + field.literal + "\n}\n")
+		default:
+			builder.WriteString("if " + selector + " == " + zeroLiteral(field.rType) + " {\n")
+			builder.WriteString(selector + " = " + field.literal + "\n}\n")
+		}
+	}
+	builder.WriteString("}")
+}
@@ -0,0 +1,83 @@
+package xreflect
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	type Audit struct {
+		By string `default:"system"`
+	}
+	type Foo struct {
+		Name    string        `default:"abc"`
+		Limit   int           `default:"10"`
+		Ratio   *float64      `default:"1.5"`
+		Timeout time.Duration `default:"5s"`
+		Active  bool          `default:"true"`
+		Audit   Audit
+		Meta    *Audit
+	}
+	ratio := 1.5
+	testCases := []struct {
+		description string
+		value       interface{}
+		expect      interface{}
+		hasError    bool
+	}{
+		{
+			description: "zero value",
+			value:       &Foo{Meta: &Audit{}},
+			expect:      &Foo{Name: "abc", Limit: 10, Ratio: &ratio, Timeout: 5 * time.Second, Active: true, Audit: Audit{By: "system"}, Meta: &Audit{By: "system"}},
+		},
+		{
+			description: "set fields are kept",
+			value:       &Foo{Name: "xyz", Limit: 3, Audit: Audit{By: "user"}},
+			expect:      &Foo{Name: "xyz", Limit: 3, Ratio: &ratio, Timeout: 5 * time.Second, Active: true, Audit: Audit{By: "user"}},
+		},
+		{
+			description: "invalid default",
+			value: &struct {
+				Limit int `default:"x"`
+			}{},
+			hasError: true,
+		},
+		{
+			description: "non pointer",
+			value:       Foo{},
+			hasError:    true,
+		},
+	}
+	for _, testCase := range testCases {
+		err := ApplyDefaults(testCase.value)
+		if testCase.hasError {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expect, testCase.value, testCase.description)
+	}
+}
+
+func TestApplyDefaults_DynamicType(t *testing.T) {
+	types := NewTypes()
+	err := types.Register("Foo", WithPackage("model"), WithTypeDefinition("struct{Name string `default:\"abc\"`; Limit *int `default:\"10\"`; Audit struct{By string `default:\"system\"`; }; }"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	rType, err := types.Lookup("Foo", WithPackage("model"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	value := reflect.New(rType)
+	if !assert.Nil(t, ApplyDefaults(value.Interface())) {
+		return
+	}
+	assert.Equal(t, "abc", value.Elem().FieldByName("Name").String())
+	assert.EqualValues(t, 10, value.Elem().FieldByName("Limit").Elem().Int())
+	assert.Equal(t, "system", value.Elem().FieldByName("Audit").FieldByName("By").String())
+}
@@ -26,6 +26,11 @@ type (
 		// inProgress tracks types currently being resolved to prevent
 		// infinite recursion on self-referencing type declarations.
 		inProgress map[string]bool
+		// placeholders records types substituted with interface{}
+		placeholders []*Placeholder
+		owner        string
+		// edges counts pointer, slice and map expressions being matched
+		edges int
 	}
 
 	Methods struct {
@@ -37,6 +42,8 @@ type (
 		path string
 		pkg  string
 		spec *ast.TypeSpec
+		//doc is type declaration doc, used when spec has no own doc
+		doc *ast.CommentGroup
 		*DirTypes
 	}
 
@@ -175,19 +182,20 @@ func (t *TypeSpec) lookupType(packagePath string, packageIdentifier string, type
 
 	if packageIdentifier != "" && packageIdentifier != t.pkg && t.module != nil {
 		imps := t.DirTypes.imports[t.path]
-		impModule := imps.lookup(packageIdentifier)
-		folder := impModule.folder(t.module)
-		subDir, ok := t.subDirs[folder]
-		subDirPath := impModule.depPath(t.moduleLocation, t.module)
-		if !ok {
-			if subDir, err = ParseTypes(subDirPath, withOptions(&t.options)); err == nil {
-				t.subDirs[folder] = subDir
+		if impModule := imps.lookup(packageIdentifier); impModule != nil {
+			folder := impModule.folder(t.module)
+			subDir, ok := t.subDirs[folder]
+			subDirPath := impModule.depPath(t.moduleLocation, t.module)
+			if !ok {
+				if subDir, err = ParseTypes(subDirPath, withDependencyOptions(&t.options)); err == nil {
+					t.subDirs[folder] = subDir
+				}
 			}
-		}
-		if subDir != nil {
-			rType, err = subDir.Type(typeName)
-			if rType != nil {
-				return rType, nil
+			if subDir != nil {
+				rType, err = subDir.Type(typeName)
+				if rType != nil {
+					return rType, nil
+				}
 			}
 		}
 	}
@@ -198,7 +206,7 @@ func (t *TypeSpec) lookupType(packagePath string, packageIdentifier string, type
 			if location != "" {
 				subDir, ok := t.subDirs[folder]
 				if !ok {
-					subDir, err = ParseTypes(location, withOptions(&t.options))
+					subDir, err = ParseTypes(location, withDependencyOptions(&t.options))
 					if err != nil {
 						return nil, err
 					}
@@ -212,11 +220,12 @@ func (t *TypeSpec) lookupType(packagePath string, packageIdentifier string, type
 	return nil, err
 }
 
-func (t *DirTypes) registerTypeSpec(path string, pkg string, spec *ast.TypeSpec) {
+func (t *DirTypes) registerTypeSpec(path string, pkg string, spec *ast.TypeSpec, declDoc *ast.CommentGroup) {
 	t.specs[spec.Name.Name] = &TypeSpec{
 		path: path,
 		pkg:  pkg,
 		spec: spec,
+		doc:  declDoc,
 	}
 	t.typesOccurrences[spec.Name.Name] = append(t.typesOccurrences[spec.Name.Name], path)
 }
@@ -227,14 +236,21 @@ func (t *DirTypes) Type(name string) (reflect.Type, error) {
 	}
 	// Prevent infinite recursion when resolving self-referencing types.
 	if t.inProgress[name] {
-		return nil, fmt.Errorf("self-referencing type detected: %s", name)
+		if t.breakCycles && t.edges > 0 {
+			return t.addPlaceholder(&Placeholder{Target: name, Reason: PlaceholderCycle}), nil
+		}
+		return nil, newTypeError(ErrCycle, name, t.specPackage(name), t.ModulePath)
 	}
 	t.inProgress[name] = true
+	placeholders := len(t.placeholders)
+	owner, edges := t.owner, t.edges
+	t.owner, t.edges = name, 0
+	defer func() { t.owner, t.edges = owner, edges }()
 	goImports := t.GoImports
 	spec, ok := t.specs[name]
 	if !ok {
 		delete(t.inProgress, name)
-		return nil, fmt.Errorf("not found type %v", name)
+		return nil, newTypeError(ErrTypeNotFound, name, "", t.ModulePath)
 	}
 	spec.DirTypes = t
 	if len(goImports) > 0 {
@@ -244,6 +260,7 @@ func (t *DirTypes) Type(name string) (reflect.Type, error) {
 	matched, err := spec.matchType(spec.pkg, &pkgPath, spec.spec, spec.spec.Type, t.GoImports)
 	if err != nil {
 		delete(t.inProgress, name)
+		t.placeholders = t.placeholders[:placeholders] //discard placeholders of unresolved type
 		return nil, err
 	}
 
@@ -252,6 +269,52 @@ func (t *DirTypes) Type(name string) (reflect.Type, error) {
 	return matched, nil
 }
 
+// TypeDoc returns type doc comment text, comments are only parsed with parser.ParseComments mode
+func (t *DirTypes) TypeDoc(name string) string {
+	spec, ok := t.specs[name]
+	if !ok {
+		return ""
+	}
+	doc := spec.spec.Doc
+	if doc == nil {
+		doc = spec.doc
+	}
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// FieldDoc returns struct type field doc or line comment text, comments are only parsed with parser.ParseComments mode
+func (t *DirTypes) FieldDoc(typeName, name string) string {
+	spec, ok := t.specs[typeName]
+	if !ok {
+		return ""
+	}
+	structType, ok := spec.spec.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 && fieldName(field) == name { //embedded field
+			return fieldDoc(field)
+		}
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return fieldDoc(field)
+			}
+		}
+	}
+	return ""
+}
+
+func (t *DirTypes) specPackage(name string) string {
+	if spec, ok := t.specs[name]; ok {
+		return spec.pkg
+	}
+	return ""
+}
+
 func (t *DirTypes) Value(symbol string) (interface{}, error) {
 	if value, ok := t.values[symbol]; ok {
 		return value, nil
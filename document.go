@@ -0,0 +1,123 @@
+package xreflect
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+type (
+	//Document represents portable registry snapshot, it can be marshaled with JSON or YAML
+	Document struct {
+		Packages []*PackageDocument `json:"packages,omitempty" yaml:"packages,omitempty"`
+	}
+
+	//PackageDocument represents registry package
+	PackageDocument struct {
+		Name       string          `json:"name" yaml:"name"`
+		ImportPath string          `json:"importPath,omitempty" yaml:"importPath,omitempty"`
+		Path       string          `json:"path,omitempty" yaml:"path,omitempty"`
+		Final      bool            `json:"final,omitempty" yaml:"final,omitempty"`
+		Imports    []string        `json:"imports,omitempty" yaml:"imports,omitempty"`
+		Types      []*TypeDocument `json:"types,omitempty" yaml:"types,omitempty"`
+	}
+
+	//TypeDocument represents registry type with its definition
+	TypeDocument struct {
+		Name       string   `json:"name" yaml:"name"`
+		Definition string   `json:"definition" yaml:"definition"`
+		Methods    []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	}
+)
+
+// Export exports registry packages and types definitions
+func (t *Types) Export() *Document {
+	result := &Document{}
+	names := t.PackageNames()
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := t.Package(name)
+		pkgDoc := &PackageDocument{Name: pkg.Name, ImportPath: pkg.ImportPath, Path: pkg.Path, Final: pkg.Final, Imports: pkg.Imports()}
+		sort.Strings(pkgDoc.Imports)
+		typeNames := pkg.TypeNames()
+		sort.Strings(typeNames)
+		for _, typeName := range typeNames {
+			rType, err := pkg.Lookup(typeName)
+			if err != nil || rType == nil {
+				continue
+			}
+			aType := &Type{Name: typeName, Package: pkg.Name, ImportPath: pkg.ImportPath, Type: rType, Registry: t}
+			typeDoc := &TypeDocument{Name: typeName, Definition: typeDefinition(aType)}
+			pkg.mux.RLock()
+			for _, method := range pkg.methods[typeName] {
+				typeDoc.Methods = append(typeDoc.Methods, method.Name)
+			}
+			pkg.mux.RUnlock()
+			pkgDoc.Types = append(pkgDoc.Types, typeDoc)
+		}
+		result.Packages = append(result.Packages, pkgDoc)
+	}
+	return result
+}
+
+// Import registers document packages and types, types are registered in dependency order
+func (t *Types) Import(document *Document) error {
+	if document == nil {
+		return nil
+	}
+	type pending struct {
+		pkg     *Package
+		typeDoc *TypeDocument
+	}
+	var candidates []*pending
+	var finals []*Package
+	for _, pkgDoc := range document.Packages {
+		pkg, err := t.importPackage(pkgDoc.ImportPath, pkgDoc.Name, pkgDoc.Path)
+		if err != nil {
+			return err
+		}
+		pkg.mux.Lock()
+		if len(pkgDoc.Imports) > 0 {
+			if pkg.imports == nil {
+				pkg.imports = map[string][]string{}
+			}
+			pkg.imports[""] = pkgDoc.Imports
+		}
+		pkg.mux.Unlock()
+		if pkgDoc.Final {
+			finals = append(finals, pkg)
+		}
+		for _, typeDoc := range pkgDoc.Types {
+			candidates = append(candidates, &pending{pkg: pkg, typeDoc: typeDoc})
+		}
+	}
+	for len(candidates) > 0 {
+		var remaining []*pending
+		var errs Errors
+		for _, candidate := range candidates {
+			typeDoc := candidate.typeDoc
+			if err := t.Register(typeDoc.Name, WithPackage(candidate.pkg.Name), WithImportPath(candidate.pkg.ImportPath), WithTypeDefinition(typeDoc.Definition)); err != nil {
+				remaining = append(remaining, candidate)
+				errs.Append(fmt.Errorf("failed to import %v.%v: %w", candidate.pkg.Name, typeDoc.Name, err))
+				continue
+			}
+			if len(typeDoc.Methods) > 0 {
+				var methods []reflect.Method
+				for _, method := range typeDoc.Methods {
+					methods = append(methods, reflect.Method{Name: method})
+				}
+				candidate.pkg.mux.Lock()
+				candidate.pkg.methods[typeDoc.Name] = methods
+				candidate.pkg.mux.Unlock()
+			}
+		}
+		if len(remaining) == len(candidates) {
+			return errs
+		}
+		candidates = remaining
+	}
+	for _, pkg := range finals {
+		pkg.Final = true
+	}
+	return nil
+}
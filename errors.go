@@ -0,0 +1,164 @@
+package xreflect
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	//ErrTypeNotFound reports type that can not be located
+	ErrTypeNotFound = errors.New("type not found")
+	//ErrPackageNotFound reports package that can not be located
+	ErrPackageNotFound = errors.New("package not found")
+	//ErrCycle reports self-referencing type
+	ErrCycle = errors.New("self-referencing type detected")
+	//ErrUnsupportedNode reports unsupported ast node
+	ErrUnsupportedNode = errors.New("unsupported node")
+	//ErrAmbiguousPackage reports short package name or alias matching more than one import path
+	ErrAmbiguousPackage = errors.New("ambiguous package")
+	//ErrSealed reports change of sealed registry
+	ErrSealed = errors.New("registry is sealed")
+	//ErrInvalidJSON reports malformed or mismatched JSON decoded by generated UnmarshalJSON
+	ErrInvalidJSON = errors.New("invalid JSON")
+	//ErrInvalidIdentifier reports generated type, package or field name that is not valid Go identifier
+	ErrInvalidIdentifier = errors.New("invalid identifier")
+	//ErrUnsupportedType reports type that can not be expressed in generated code
+	ErrUnsupportedType = errors.New("unsupported type")
+	//ErrInvalidDefault reports default tag value that can not be parsed for field type
+	ErrInvalidDefault = errors.New("invalid default")
+	//ErrInvalidBuildConstraint reports generated source build constraint that does not parse
+	ErrInvalidBuildConstraint = errors.New("invalid build constraint")
+	//ErrInvalidSource reports generated source that does not parse
+	ErrInvalidSource = errors.New("invalid generated source")
+)
+
+// TypeError represents type resolution error, use errors.Is with sentinel errors to check its kind
+type TypeError struct {
+	Err         error
+	Name        string
+	Package     string
+	PackagePath string
+}
+
+// Error returns error message
+func (e *TypeError) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(e.Err.Error())
+	builder.WriteString(": ")
+	builder.WriteString(e.Name)
+	if e.Package != "" {
+		builder.WriteString(" in package: ")
+		builder.WriteString(e.Package)
+	}
+	if e.PackagePath != "" {
+		builder.WriteString(" (")
+		builder.WriteString(e.PackagePath)
+		builder.WriteString(")")
+	}
+	return builder.String()
+}
+
+// Unwrap returns sentinel error
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+func newTypeError(err error, name, pkg, pkgPath string) error {
+	return &TypeError{Err: err, Name: name, Package: pkg, PackagePath: pkgPath}
+}
+
+// GenerateError represents code generation error, use errors.Is with sentinel errors to check its kind
+type GenerateError struct {
+	Err  error
+	Name string
+	//Cause is underlying parser error
+	Cause error
+	//Line is offending generated source line
+	Line string
+}
+
+// Error returns error message
+func (e *GenerateError) Error() string {
+	builder := strings.Builder{}
+	builder.WriteString(e.Err.Error())
+	builder.WriteString(": ")
+	builder.WriteString(e.Name)
+	if e.Cause != nil {
+		builder.WriteString(": ")
+		builder.WriteString(e.Cause.Error())
+	}
+	if e.Line != "" {
+		builder.WriteString(", source: ")
+		builder.WriteString(strings.TrimSpace(e.Line))
+	}
+	return builder.String()
+}
+
+// Unwrap returns sentinel error
+func (e *GenerateError) Unwrap() error {
+	return e.Err
+}
+
+// AmbiguousPackageError represents package name matching more than one registry package
+type AmbiguousPackageError struct {
+	Name       string
+	Candidates []string
+}
+
+// Error returns error message
+func (e *AmbiguousPackageError) Error() string {
+	return ErrAmbiguousPackage.Error() + ": " + e.Name + ", candidates: " + strings.Join(e.Candidates, ", ")
+}
+
+// Unwrap returns ErrAmbiguousPackage
+func (e *AmbiguousPackageError) Unwrap() error {
+	return ErrAmbiguousPackage
+}
+
+// Errors represents multi error
+type Errors []error
+
+// Error returns errors message
+func (e Errors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is returns true if any error matches target
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error that matches target
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Append appends non nil error
+func (e *Errors) Append(err error) {
+	if err == nil {
+		return
+	}
+	*e = append(*e, err)
+}
+
+// Err returns nil if no errors were collected
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
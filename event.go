@@ -0,0 +1,86 @@
+package xreflect
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EventKind represents registry event kind
+type EventKind string
+
+const (
+	//EventRegister type was registered under a new name
+	EventRegister = EventKind("register")
+	//EventReplace type replaced previously registered type
+	EventReplace = EventKind("replace")
+	//EventUnregister type was removed
+	EventUnregister = EventKind("unregister")
+	//EventPackageCreated package was added to the registry
+	EventPackageCreated = EventKind("packageCreated")
+	//EventLookupMiss type could not be located
+	EventLookupMiss = EventKind("lookupMiss")
+)
+
+type (
+	//Event represents registry change or lookup miss
+	Event struct {
+		Kind     EventKind
+		Package  string
+		Name     string
+		Type     reflect.Type
+		Previous reflect.Type
+		Err      error
+	}
+
+	//Listener represents registry event listener
+	Listener func(event *Event)
+
+	listeners struct {
+		mux   sync.RWMutex
+		seq   int
+		items map[int]Listener
+	}
+)
+
+// Subscribe adds registry event listener, listeners are called synchronously outside registry locks,
+// returned function removes the listener
+func (t *Types) Subscribe(listener Listener) func() {
+	t.listeners.mux.Lock()
+	defer t.listeners.mux.Unlock()
+	if t.listeners.items == nil {
+		t.listeners.items = map[int]Listener{}
+	}
+	t.listeners.seq++
+	id := t.listeners.seq
+	t.listeners.items[id] = listener
+	return func() {
+		t.listeners.mux.Lock()
+		delete(t.listeners.items, id)
+		t.listeners.mux.Unlock()
+	}
+}
+
+func (t *Types) notify(event *Event) {
+	t.listeners.mux.RLock()
+	if len(t.listeners.items) == 0 {
+		t.listeners.mux.RUnlock()
+		return
+	}
+	var subscribed []Listener
+	for _, listener := range t.listeners.items {
+		subscribed = append(subscribed, listener)
+	}
+	t.listeners.mux.RUnlock()
+	for _, listener := range subscribed {
+		listener(event)
+	}
+}
+
+// notifyChange notifies register or replace event
+func (t *Types) notifyChange(pkg, name string, previous, rType reflect.Type) {
+	event := &Event{Kind: EventRegister, Package: pkg, Name: name, Type: rType, Previous: previous}
+	if previous != nil {
+		event.Kind = EventReplace
+	}
+	t.notify(event)
+}
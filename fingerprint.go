@@ -0,0 +1,113 @@
+package xreflect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// fingerprintIndex represents immutable structural index of registered struct types
+type fingerprintIndex struct {
+	generation uint64
+	types      map[string][]*Type
+}
+
+// Fingerprint returns canonical structural fingerprint of supplied type,
+// struct type names are ignored, so named and anonymous structs with the same fields, types and tags share fingerprint
+func Fingerprint(rType reflect.Type) string {
+	builder := &strings.Builder{}
+	appendFingerprint(builder, rType, map[reflect.Type]bool{})
+	hash := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(hash[:])
+}
+
+func appendFingerprint(builder *strings.Builder, rType reflect.Type, visiting map[reflect.Type]bool) {
+	if rType.Name() != "" && (rType.Kind() != reflect.Struct || visiting[rType]) {
+		builder.WriteString(rType.PkgPath())
+		builder.WriteByte('.')
+		builder.WriteString(rType.Name())
+		return
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		builder.WriteByte('*')
+		appendFingerprint(builder, rType.Elem(), visiting)
+	case reflect.Slice:
+		builder.WriteString("[]")
+		appendFingerprint(builder, rType.Elem(), visiting)
+	case reflect.Array:
+		builder.WriteString("[" + strconv.Itoa(rType.Len()) + "]")
+		appendFingerprint(builder, rType.Elem(), visiting)
+	case reflect.Map:
+		builder.WriteString("map[")
+		appendFingerprint(builder, rType.Key(), visiting)
+		builder.WriteByte(']')
+		appendFingerprint(builder, rType.Elem(), visiting)
+	case reflect.Struct:
+		visiting[rType] = true
+		builder.WriteString("struct{")
+		for i := 0; i < rType.NumField(); i++ {
+			field := rType.Field(i)
+			if field.Anonymous {
+				builder.WriteString("embedded ")
+			}
+			builder.WriteString(field.Name)
+			builder.WriteByte(' ')
+			appendFingerprint(builder, field.Type, visiting)
+			builder.WriteByte(' ')
+			builder.WriteString(strconv.Quote(string(field.Tag)))
+			builder.WriteByte(';')
+		}
+		builder.WriteByte('}')
+		delete(visiting, rType)
+	default:
+		builder.WriteString(rType.String())
+	}
+}
+
+// Matches returns registered struct types structurally equal to supplied type, sorted by type name
+func (t *Types) Matches(rType reflect.Type) []*Type {
+	if rType == nil || rType.Kind() != reflect.Struct {
+		return nil
+	}
+	return t.fingerprintIndex().types[Fingerprint(rType)]
+}
+
+// matchInfo returns registered type info structurally equal to anonymous struct, or nil if there is no unique match
+func (t *Types) matchInfo(rType reflect.Type) *Type {
+	if rType.Name() != "" {
+		return nil
+	}
+	if matches := t.Matches(rType); len(matches) == 1 {
+		return matches[0]
+	}
+	return nil
+}
+
+func (t *Types) fingerprintIndex() *fingerprintIndex {
+	generation := atomic.LoadUint64(&t.generation)
+	if index, _ := t.fingerprints.Load().(*fingerprintIndex); index != nil && index.generation == generation {
+		return index
+	}
+	index := &fingerprintIndex{generation: generation, types: map[string][]*Type{}}
+	t.mux.RLock()
+	for rType, info := range t.info {
+		if rType.Kind() != reflect.Struct {
+			continue
+		}
+		fingerprint := Fingerprint(rType)
+		index.types[fingerprint] = append(index.types[fingerprint], info)
+	}
+	t.mux.RUnlock()
+	for _, candidates := range index.types {
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].TypeName() < candidates[j].TypeName()
+		})
+	}
+	t.fingerprints.Store(index)
+	return index
+}
@@ -0,0 +1,197 @@
+package xreflect
+
+import (
+	"bytes"
+	"go/build/constraint"
+	"go/format"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GeneratedHeader marks files owned by GeneratePackage
+const GeneratedHeader = "// Code generated by xreflect. DO NOT EDIT."
+
+// PackageFiles represents GeneratePackage outcome, file names are relative to package directory
+type PackageFiles struct {
+	Written   []string
+	Unchanged []string
+	Removed   []string
+}
+
+type generatedFile struct {
+	name  string
+	types []*Type
+}
+
+// GeneratePackage writes supplied types to package directory, one file per type unless WithFileName groups types.
+// Files are rewritten only when changed, previously generated files that are no longer produced are removed.
+// Invalid identifiers, unsupported types, invalid defaults and source that does not format are reported as GenerateError.
+func GeneratePackage(types []*Type, dir string, opts ...Option) (*PackageFiles, error) {
+	genOptions := &options{}
+	genOptions.Apply(opts...)
+	if genOptions.Package == "" && len(types) > 0 {
+		genOptions.Package = types[0].Package
+	}
+	genOptions.initGen()
+	for _, aType := range types { //package types are never generated as nested types of other types
+		if err := validateGenerate(aType.Name, aType.Type, genOptions); err != nil {
+			return nil, err
+		}
+		genOptions.buildTypes[aType.Name] = true
+	}
+	files := groupFiles(types, genOptions.fileName)
+	result := &PackageFiles{}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	generated := map[string]bool{}
+	for _, file := range files {
+		generated[file.name] = true
+		source, err := generateFile(file, genOptions)
+		if err != nil {
+			return nil, err
+		}
+		location := path.Join(dir, file.name)
+		if existing, err := os.ReadFile(location); err == nil && bytes.Equal(existing, source) {
+			result.Unchanged = append(result.Unchanged, file.name)
+			continue
+		}
+		if err := os.WriteFile(location, source, 0644); err != nil {
+			return nil, err
+		}
+		result.Written = append(result.Written, file.name)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || generated[name] || !isGeneratedFile(path.Join(dir, name)) {
+			continue
+		}
+		if err = os.Remove(path.Join(dir, name)); err != nil {
+			return nil, err
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	return result, nil
+}
+
+// groupFiles groups types by file name, files are sorted by name, types keep supplied order
+func groupFiles(types []*Type, fileName func(aType *Type) string) []*generatedFile {
+	if fileName == nil {
+		fileName = defaultFileName
+	}
+	var files []*generatedFile
+	index := map[string]*generatedFile{}
+	for _, aType := range types {
+		name := fileName(aType)
+		if !strings.HasSuffix(name, ".go") {
+			name += ".go"
+		}
+		file, ok := index[name]
+		if !ok {
+			file = &generatedFile{name: name}
+			index[name] = file
+			files = append(files, file)
+		}
+		file.types = append(file.types, aType)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+	return files
+}
+
+// generateFile returns formatted file source, source that does not format is reported as GenerateError
+func generateFile(file *generatedFile, genOptions *options) ([]byte, error) {
+	importsBuilder := &strings.Builder{}
+	imports := newImportSet(nil)
+	for _, imported := range genOptions.imports {
+		imports.qualifier(imported, path.Base(imported))
+		importsBuilder.WriteByte('"')
+		importsBuilder.WriteString(imported)
+		importsBuilder.WriteString("\"\n")
+	}
+	var typeBuilders []*strings.Builder
+	for _, aType := range file.types {
+		rType := aType.Type
+		if rType.Kind() == reflect.Ptr {
+			rType = rType.Elem()
+		}
+		typeBuilder := newTypeBuilder(aType.Name, aType.Doc)
+		dependencies := buildGoType(typeBuilder, importsBuilder, aType.Name, rType, imports, genOptions)
+		typeBuilders = append(typeBuilders, typeBuilder)
+		for _, dependency := range dependencies {
+			if dependency.Len() > 0 {
+				typeBuilders = append(typeBuilders, dependency)
+			}
+		}
+	}
+	header, err := fileHeader(true, genOptions.buildConstraint)
+	if err != nil {
+		return nil, err
+	}
+	generated := header + build(importsBuilder, typeBuilders[0], typeBuilders[1:], genOptions.snippetBefore, genOptions.Package)
+	if genOptions.snippetAfter != "" {
+		generated += genOptions.snippetAfter
+	}
+	source, err := format.Source([]byte(generated))
+	if err != nil {
+		return nil, newSourceError(file.name, generated, err)
+	}
+	return source, nil
+}
+
+// fileHeader returns generated source header followed by build constraint lines, invalid constraint is reported as GenerateError
+func fileHeader(header bool, buildConstraint string) (string, error) {
+	result := strings.Builder{}
+	if header {
+		result.WriteString(GeneratedHeader + "\n\n")
+	}
+	if buildConstraint == "" {
+		return result.String(), nil
+	}
+	expr, err := constraint.Parse("//go:build " + buildConstraint)
+	if err != nil {
+		return result.String(), &GenerateError{Err: ErrInvalidBuildConstraint, Name: strconv.Quote(buildConstraint), Cause: err}
+	}
+	result.WriteString("//go:build " + buildConstraint + "\n")
+	if lines, err := constraint.PlusBuildLines(expr); err == nil { //go 1.16 compatible constraint
+		for _, line := range lines {
+			result.WriteString(line + "\n")
+		}
+	}
+	result.WriteString("\n")
+	return result.String(), nil
+}
+
+func isGeneratedFile(location string) bool {
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return false
+	}
+	return bytes.HasPrefix(data, []byte(GeneratedHeader))
+}
+
+// defaultFileName returns snake case type name
+func defaultFileName(aType *Type) string {
+	builder := strings.Builder{}
+	runes := []rune(aType.Name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,89 @@
+package xreflect
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePackage(t *testing.T) {
+	itemType := reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType}, {Name: "Created", Type: TimeType}})
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Items", Type: reflect.SliceOf(itemType), Tag: `typeName:"Item"`}})
+	customerType := reflect.StructOf([]reflect.StructField{{Name: "Name", Type: StringType}, {Name: "Orders", Type: reflect.SliceOf(reflect.PtrTo(orderType)), Tag: `typeName:"Order"`}})
+	types := []*Type{
+		NewType("Order", WithPackage("model"), WithReflectType(orderType)),
+		NewType("CustomerAccount", WithPackage("model"), WithReflectType(customerType)),
+	}
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(path.Join(dir, "custom.go"), []byte("package model\n"), 0644))
+
+	files, err := GeneratePackage(types, dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"customer_account.go", "order.go"}, files.Written)
+	order, _ := os.ReadFile(path.Join(dir, "order.go"))
+	assert.Equal(t, GeneratedHeader+`
+
+package model
+
+import (
+	"time"
+)
+
+type Order struct {
+	ID    int
+	Items []Item
+}
+
+type Item struct {
+	SKU     string
+	Created time.Time
+}
+`, string(order))
+	customer, _ := os.ReadFile(path.Join(dir, "customer_account.go"))
+	assert.True(t, strings.Contains(string(customer), "Orders []*Order"))
+	assert.False(t, strings.Contains(string(customer), "import"))
+	assert.False(t, strings.Contains(string(customer), "type Order"))
+
+	files, err = GeneratePackage(types, dir)
+	assert.Nil(t, err)
+	assert.Empty(t, files.Written)
+	assert.Equal(t, []string{"customer_account.go", "order.go"}, files.Unchanged)
+
+	files, err = GeneratePackage(types[:1], dir, WithFileName(func(aType *Type) string {
+		return "types"
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"types.go"}, files.Written)
+	assert.Equal(t, []string{"customer_account.go", "order.go"}, files.Removed)
+	_, err = os.Stat(path.Join(dir, "custom.go"))
+	assert.Nil(t, err)
+}
+
+func TestGeneratePackage_InvalidSource(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	dir := t.TempDir()
+	_, err := GeneratePackage([]*Type{NewType("Order", WithPackage("model"), WithReflectType(orderType))}, dir, WithSnippetAfter("\nfunc Invalid( {\n"))
+	assert.True(t, errors.Is(err, ErrInvalidSource))
+	generateErr := &GenerateError{}
+	if assert.True(t, errors.As(err, &generateErr)) {
+		assert.Equal(t, "order.go", generateErr.Name)
+		assert.Equal(t, "func Invalid( {", generateErr.Line)
+	}
+	_, err = os.Stat(path.Join(dir, "order.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGeneratePackage_InvalidDefault(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "Ratio", Type: reflect.TypeOf(0.0), Tag: `default:"high"`}})
+	dir := t.TempDir()
+	_, err := GeneratePackage([]*Type{NewType("Order", WithPackage("model"), WithReflectType(orderType))}, dir, WithConstructors(true))
+	assert.True(t, errors.Is(err, ErrInvalidDefault))
+	_, err = os.Stat(path.Join(dir, "order.go"))
+	assert.True(t, os.IsNotExist(err))
+}
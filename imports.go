@@ -0,0 +1,95 @@
+package xreflect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// importSet assigns package qualifiers to import paths, colliding package names are aliased in order of appearance
+type importSet struct {
+	qualifiers map[string]string //import path to qualifier
+	used       map[string]string //qualifier to import path
+}
+
+func newImportSet(imports GoImports) *importSet {
+	ret := &importSet{qualifiers: map[string]string{}, used: map[string]string{}}
+	for _, imp := range imports {
+		if imp.Name != "" && imp.Name != "_" && imp.Name != "." {
+			ret.add(imp.Module, imp.Name)
+		}
+	}
+	return ret
+}
+
+// qualifier returns package qualifier for import path, package name is used unless it was taken by another import path
+func (s *importSet) qualifier(importPath, pkgName string) string {
+	if s == nil || importPath == "" || pkgName == "" {
+		return pkgName
+	}
+	if qualifier, ok := s.qualifiers[importPath]; ok {
+		return qualifier
+	}
+	qualifier := pkgName
+	if _, taken := s.used[qualifier]; taken {
+		qualifier = s.alias(importPath, pkgName)
+	}
+	s.add(importPath, qualifier)
+	return qualifier
+}
+
+func (s *importSet) has(importPath string) bool {
+	_, ok := s.qualifiers[importPath]
+	return ok
+}
+
+func (s *importSet) add(importPath, qualifier string) {
+	s.qualifiers[importPath] = qualifier
+	s.used[qualifier] = importPath
+}
+
+// alias returns parent directory prefixed package name i.e. htmltemplate for html/template, or numbered package name
+func (s *importSet) alias(importPath, pkgName string) string {
+	if segments := strings.Split(importPath, "/"); len(segments) > 1 {
+		candidate := identifier(segments[len(segments)-2]) + pkgName
+		if _, taken := s.used[candidate]; !taken {
+			return candidate
+		}
+	}
+	for i := 2; ; i++ {
+		candidate := pkgName + strconv.Itoa(i)
+		if _, taken := s.used[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+func identifier(segment string) string {
+	builder := strings.Builder{}
+	for _, r := range strings.ToLower(segment) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9' && builder.Len() > 0) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// packageName returns package name of a named type
+func packageName(rType reflect.Type) string {
+	if rType.Name() == "" || rType.PkgPath() == "" {
+		return ""
+	}
+	name := rType.String()
+	if index := strings.Index(name, "."); index != -1 {
+		return name[:index]
+	}
+	return ""
+}
+
+// qualifiedName returns named type name with supplied package qualifier
+func qualifiedName(rType reflect.Type, qualifier string) string {
+	if rType.Name() == "" || rType.PkgPath() == "" || qualifier == "" {
+		return rType.String()
+	}
+	return qualifier + "." + rType.Name()
+}
@@ -0,0 +1,8 @@
+package api
+
+import "github.com/viant/xreflect/internal/testdata/collect/model"
+
+type Response struct {
+	Status string
+	Order  *model.Order
+}
@@ -0,0 +1,12 @@
+package model
+
+import "github.com/acme/crm"
+
+type Order struct {
+	ID   int
+	Name string
+}
+
+type Broken struct {
+	Customer *crm.Customer
+}
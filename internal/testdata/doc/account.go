@@ -0,0 +1,12 @@
+package doc
+
+// Account represents user account
+//
+// It is used to test generated doc comments.
+type Account struct {
+	// ID is account identifier
+	ID    int    `json:"id"`
+	Name  string // Name is display name
+	Email string `json:"email" description:"primary email"`
+	Note  string
+}
@@ -0,0 +1,15 @@
+package lenient
+
+import "github.com/acme/crm"
+
+type Order struct {
+	ID       int
+	Customer *crm.Customer
+	Items    []*Item
+	Audit    Audit `json:",omitempty"`
+}
+
+type Item struct {
+	SKU      string
+	Quantity int
+}
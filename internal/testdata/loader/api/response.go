@@ -0,0 +1,9 @@
+package api
+
+import "github.com/viant/xreflect/internal/testdata/loader/model"
+
+type Response struct {
+	Status string
+	Order  *model.Order
+	Orders []model.Order
+}
@@ -0,0 +1,12 @@
+package model
+
+type Order struct {
+	ID    int
+	Name  string
+	Items []*Item
+}
+
+type Item struct {
+	SKU      string
+	Quantity int
+}
@@ -0,0 +1,17 @@
+package testdata
+
+type Node struct {
+	ID       int
+	Children []*Node
+	Index    map[string]*Node
+}
+
+type Parent struct {
+	Name  string
+	Child *Child
+}
+
+type Child struct {
+	Name   string
+	Parent *Parent
+}
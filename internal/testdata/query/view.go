@@ -0,0 +1,28 @@
+package query
+
+type Audit struct {
+	CreatedBy string
+}
+
+type Order struct {
+	Audit
+	ID    int
+	Items []*Item `xdatly:"kind:data_view"`
+}
+
+type Item struct {
+	SKU      string
+	Quantity int
+}
+
+type Customer struct {
+	ID     int
+	Orders []*Order `xdatly:"kind:data_view,cache"`
+	Name   string   `json:"name"`
+}
+
+type Status int
+
+func (o *Order) Validate() error {
+	return nil
+}
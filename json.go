@@ -0,0 +1,562 @@
+package xreflect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSON helpers used by generated MarshalJSON and UnmarshalJSON methods, they follow encoding/json encoding rules
+
+const hexDigits = "0123456789abcdef"
+
+// AppendJSONString appends JSON quoted string, HTML characters and invalid UTF-8 are escaped like in encoding/json
+func AppendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', 'f', 'f', 'f', 'd')
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// AppendJSONFloat appends JSON number, NaN and infinity are reported as error
+func AppendJSONFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return dst, fmt.Errorf("unsupported JSON value: %v", strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' { //clean up e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
+
+// AppendJSONBytes appends base64 encoded JSON string
+func AppendJSONBytes(dst []byte, b []byte) []byte {
+	dst = append(dst, '"')
+	dst = append(dst, base64.StdEncoding.EncodeToString(b)...)
+	return append(dst, '"')
+}
+
+// AppendJSONMarshaler appends compacted json.Marshaler output
+func AppendJSONMarshaler(dst []byte, marshaler json.Marshaler) ([]byte, error) {
+	data, err := marshaler.MarshalJSON()
+	if err != nil {
+		return dst, err
+	}
+	buffer := bytes.NewBuffer(dst)
+	if err = json.Compact(buffer, data); err != nil {
+		return dst, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// AppendJSONValue appends value encoded with encoding/json, it is used for interfaces and types without generated methods
+func AppendJSONValue(dst []byte, value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, data...), nil
+}
+
+// IsJSONNull returns true if data is JSON null
+func IsJSONNull(data []byte) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+// MatchJSONKey returns index of matching field name, exact match is preferred over case insensitive one, -1 if none matches
+func MatchJSONKey(key string, names ...string) int {
+	for i, name := range names {
+		if name == key {
+			return i
+		}
+	}
+	for i, name := range names {
+		if strings.EqualFold(name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ScanJSONObject calls fn for each object member, null is ignored
+func ScanJSONObject(data []byte, fn func(key string, value []byte) error) error {
+	i := skipJSONSpace(data, 0)
+	if IsJSONNull(data) {
+		return nil
+	}
+	if i >= len(data) || data[i] != '{' {
+		return newJSONTypeError(data, "object")
+	}
+	i = skipJSONSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return expectJSONEnd(data, i+1)
+	}
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return newJSONSyntaxError(i, "object key")
+		}
+		end, err := scanJSONString(data, i)
+		if err != nil {
+			return err
+		}
+		key, err := unquoteJSON(data[i:end])
+		if err != nil {
+			return err
+		}
+		i = skipJSONSpace(data, end)
+		if i >= len(data) || data[i] != ':' {
+			return newJSONSyntaxError(i, "':'")
+		}
+		i = skipJSONSpace(data, i+1)
+		if end, err = scanJSONValue(data, i); err != nil {
+			return err
+		}
+		if err = fn(key, data[i:end]); err != nil {
+			return err
+		}
+		i = skipJSONSpace(data, end)
+		if i < len(data) && data[i] == ',' {
+			i = skipJSONSpace(data, i+1)
+			continue
+		}
+		if i < len(data) && data[i] == '}' {
+			return expectJSONEnd(data, i+1)
+		}
+		return newJSONSyntaxError(i, "',' or '}'")
+	}
+}
+
+// ScanJSONArray calls fn for each array item, null is ignored
+func ScanJSONArray(data []byte, fn func(item []byte) error) error {
+	i := skipJSONSpace(data, 0)
+	if IsJSONNull(data) {
+		return nil
+	}
+	if i >= len(data) || data[i] != '[' {
+		return newJSONTypeError(data, "array")
+	}
+	i = skipJSONSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return expectJSONEnd(data, i+1)
+	}
+	for {
+		end, err := scanJSONValue(data, i)
+		if err != nil {
+			return err
+		}
+		if err = fn(data[i:end]); err != nil {
+			return err
+		}
+		i = skipJSONSpace(data, end)
+		if i < len(data) && data[i] == ',' {
+			i = skipJSONSpace(data, i+1)
+			continue
+		}
+		if i < len(data) && data[i] == ']' {
+			return expectJSONEnd(data, i+1)
+		}
+		return newJSONSyntaxError(i, "',' or ']'")
+	}
+}
+
+// DecodeJSONString decodes JSON string
+func DecodeJSONString(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '"' {
+		return "", newJSONTypeError(data, "string")
+	}
+	end, err := scanJSONString(data, 0)
+	if err != nil {
+		return "", err
+	}
+	if err = expectJSONEnd(data, end); err != nil {
+		return "", err
+	}
+	return unquoteJSON(data)
+}
+
+// DecodeJSONQuoted decodes JSON string holding JSON literal, it is used for fields with ",string" option
+func DecodeJSONQuoted(data []byte) ([]byte, error) {
+	if IsJSONNull(data) {
+		return data, nil
+	}
+	literal, err := DecodeJSONString(data)
+	return []byte(literal), err
+}
+
+// DecodeJSONBytes decodes base64 encoded JSON string
+func DecodeJSONBytes(data []byte) ([]byte, error) {
+	encoded, err := DecodeJSONString(data)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
+
+// DecodeJSONBool decodes JSON boolean
+func DecodeJSONBool(data []byte) (bool, error) {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, newJSONTypeError(data, "bool")
+}
+
+// DecodeJSONInt decodes JSON number as integer of supplied bit size
+func DecodeJSONInt(data []byte, bits int) (int64, error) {
+	literal, err := jsonNumber(data)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseInt(literal, 10, bits)
+	if err != nil {
+		return 0, newJSONTypeError(data, "int"+strconv.Itoa(bits))
+	}
+	return value, nil
+}
+
+// DecodeJSONUint decodes JSON number as unsigned integer of supplied bit size
+func DecodeJSONUint(data []byte, bits int) (uint64, error) {
+	literal, err := jsonNumber(data)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(literal, 10, bits)
+	if err != nil {
+		return 0, newJSONTypeError(data, "uint"+strconv.Itoa(bits))
+	}
+	return value, nil
+}
+
+// DecodeJSONFloat decodes JSON number as float of supplied bit size
+func DecodeJSONFloat(data []byte, bits int) (float64, error) {
+	literal, err := jsonNumber(data)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(literal, bits)
+	if err != nil {
+		return 0, newJSONTypeError(data, "float"+strconv.Itoa(bits))
+	}
+	return value, nil
+}
+
+func jsonNumber(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '-' && (data[0] < '0' || data[0] > '9')) {
+		return "", newJSONTypeError(data, "number")
+	}
+	end, err := scanJSONNumber(data, 0)
+	if err != nil {
+		return "", err
+	}
+	if err = expectJSONEnd(data, end); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func expectJSONEnd(data []byte, i int) error {
+	if i = skipJSONSpace(data, i); i != len(data) {
+		return newJSONSyntaxError(i, "end of value")
+	}
+	return nil
+}
+
+// scanJSONValue validates value starting at i and returns its end offset
+func scanJSONValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return i, newJSONSyntaxError(i, "value")
+	}
+	switch c := data[i]; {
+	case c == '"':
+		return scanJSONString(data, i)
+	case c == '{':
+		i = skipJSONSpace(data, i+1)
+		if i < len(data) && data[i] == '}' {
+			return i + 1, nil
+		}
+		for {
+			if i >= len(data) || data[i] != '"' {
+				return i, newJSONSyntaxError(i, "object key")
+			}
+			end, err := scanJSONString(data, i)
+			if err != nil {
+				return end, err
+			}
+			i = skipJSONSpace(data, end)
+			if i >= len(data) || data[i] != ':' {
+				return i, newJSONSyntaxError(i, "':'")
+			}
+			if i, err = scanJSONValue(data, skipJSONSpace(data, i+1)); err != nil {
+				return i, err
+			}
+			i = skipJSONSpace(data, i)
+			if i < len(data) && data[i] == ',' {
+				i = skipJSONSpace(data, i+1)
+				continue
+			}
+			if i < len(data) && data[i] == '}' {
+				return i + 1, nil
+			}
+			return i, newJSONSyntaxError(i, "',' or '}'")
+		}
+	case c == '[':
+		i = skipJSONSpace(data, i+1)
+		if i < len(data) && data[i] == ']' {
+			return i + 1, nil
+		}
+		for {
+			var err error
+			if i, err = scanJSONValue(data, i); err != nil {
+				return i, err
+			}
+			i = skipJSONSpace(data, i)
+			if i < len(data) && data[i] == ',' {
+				i = skipJSONSpace(data, i+1)
+				continue
+			}
+			if i < len(data) && data[i] == ']' {
+				return i + 1, nil
+			}
+			return i, newJSONSyntaxError(i, "',' or ']'")
+		}
+	case c == 't':
+		return scanJSONLiteral(data, i, "true")
+	case c == 'f':
+		return scanJSONLiteral(data, i, "false")
+	case c == 'n':
+		return scanJSONLiteral(data, i, "null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return scanJSONNumber(data, i)
+	}
+	return i, newJSONSyntaxError(i, "value")
+}
+
+func scanJSONLiteral(data []byte, i int, literal string) (int, error) {
+	if !bytes.HasPrefix(data[i:], []byte(literal)) {
+		return i, newJSONSyntaxError(i, literal)
+	}
+	return i + len(literal), nil
+}
+
+func scanJSONNumber(data []byte, i int) (int, error) {
+	start := i
+	if i < len(data) && data[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(data) && data[i] == '0':
+		i++
+	case i < len(data) && data[i] >= '1' && data[i] <= '9':
+		i = scanJSONDigits(data, i)
+	default:
+		return i, newJSONSyntaxError(start, "number")
+	}
+	if i < len(data) && data[i] == '.' {
+		if i = scanJSONDigits(data, i+1); data[i-1] == '.' {
+			return i, newJSONSyntaxError(start, "number")
+		}
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		exponent := i
+		if i = scanJSONDigits(data, i); i == exponent {
+			return i, newJSONSyntaxError(start, "number")
+		}
+	}
+	return i, nil
+}
+
+func scanJSONDigits(data []byte, i int) int {
+	for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// scanJSONString validates string starting at quote at i and returns offset after closing quote
+func scanJSONString(data []byte, i int) (int, error) {
+	start := i
+	for i++; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch data[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(data) {
+					return i, newJSONSyntaxError(i, "unicode escape")
+				}
+				for _, h := range data[i+1 : i+5] {
+					if !isHexDigit(h) {
+						return i, newJSONSyntaxError(i, "unicode escape")
+					}
+				}
+				i += 4
+			default:
+				return i, newJSONSyntaxError(i, "escape character")
+			}
+		case c < 0x20:
+			return i, newJSONSyntaxError(i, "string character")
+		}
+	}
+	return i, newJSONSyntaxError(start, "closing quote")
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// unquoteJSON decodes validated quoted JSON string, invalid UTF-8 and surrogates are replaced with U+FFFD
+func unquoteJSON(quoted []byte) (string, error) {
+	data := quoted[1 : len(quoted)-1]
+	if bytes.IndexByte(data, '\\') == -1 && utf8.Valid(data) {
+		return string(data), nil
+	}
+	builder := strings.Builder{}
+	for i := 0; i < len(data); {
+		c := data[i]
+		if c == '\\' {
+			i++
+			switch data[i] {
+			case 'b':
+				builder.WriteByte('\b')
+			case 'f':
+				builder.WriteByte('\f')
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case 'u':
+				r := hexRune(data[i+1 : i+5])
+				i += 4
+				if utf16.IsSurrogate(r) {
+					if i+6 < len(data) && data[i+1] == '\\' && data[i+2] == 'u' {
+						if pair := utf16.DecodeRune(r, hexRune(data[i+3:i+7])); pair != utf8.RuneError {
+							builder.WriteRune(pair)
+							i += 7
+							continue
+						}
+					}
+					r = utf8.RuneError
+				}
+				builder.WriteRune(r)
+			default:
+				builder.WriteByte(data[i])
+			}
+			i++
+			continue
+		}
+		if c < utf8.RuneSelf {
+			builder.WriteByte(c)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		builder.WriteRune(r)
+		i += size
+	}
+	return builder.String(), nil
+}
+
+func hexRune(data []byte) rune {
+	value, _ := strconv.ParseUint(string(data), 16, 32)
+	return rune(value)
+}
+
+func newJSONSyntaxError(offset int, expected string) error {
+	return fmt.Errorf("%w: expected %v at offset %v", ErrInvalidJSON, expected, offset)
+}
+
+func newJSONTypeError(data []byte, expected string) error {
+	value := string(bytes.TrimSpace(data))
+	if len(value) > 32 {
+		value = value[:32] + "..."
+	}
+	return fmt.Errorf("%w: cannot decode %v into %v", ErrInvalidJSON, value, expected)
+}
@@ -0,0 +1,140 @@
+package xreflect
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	testCases := []string{
+		"",
+		"abc",
+		"quote \" and \\ backslash",
+		"<script>&</script>",
+		"control \x00\x01\t\n\r\x1f",
+		"unicode é 世界 😀",
+		"line \u2028 and \u2029 separators",
+	}
+	for _, testCase := range testCases {
+		expected, err := json.Marshal(testCase)
+		assert.Nil(t, err)
+		assert.EqualValues(t, string(expected), string(AppendJSONString(nil, testCase)), testCase)
+	}
+
+	//invalid UTF-8 escaping differs across Go releases, only decoded value is compared
+	var actual string
+	assert.Nil(t, json.Unmarshal(AppendJSONString(nil, "invalid \xff\xfe utf8"), &actual))
+	assert.EqualValues(t, "invalid \ufffd\ufffd utf8", actual)
+}
+
+func TestAppendJSONFloat(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       float64
+		bits        int
+		expectErr   bool
+	}{
+		{description: "zero", value: 0, bits: 64},
+		{description: "integer", value: 42, bits: 64},
+		{description: "fraction", value: 0.1, bits: 64},
+		{description: "small exponent", value: 1e-7, bits: 64},
+		{description: "large exponent", value: 1e21, bits: 64},
+		{description: "float32", value: float64(float32(3.14)), bits: 32},
+		{description: "float32 exponent", value: float64(float32(1e-9)), bits: 32},
+		{description: "NaN", value: math.NaN(), bits: 64, expectErr: true},
+		{description: "infinity", value: math.Inf(1), bits: 64, expectErr: true},
+	}
+	for _, testCase := range testCases {
+		actual, err := AppendJSONFloat(nil, testCase.value, testCase.bits)
+		if testCase.expectErr {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		assert.Nil(t, err, testCase.description)
+		var expected []byte
+		if testCase.bits == 32 {
+			expected, _ = json.Marshal(float32(testCase.value))
+		} else {
+			expected, _ = json.Marshal(testCase.value)
+		}
+		assert.EqualValues(t, string(expected), string(actual), testCase.description)
+	}
+}
+
+func TestScanJSONObject(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		expect      map[string]string
+		expectErr   bool
+	}{
+		{description: "members", data: ` { "a" : 1, "b":"x\"y", "c": [1, {"d": null}] , "e": {}} `, expect: map[string]string{"a": "1", "b": `"x\"y"`, "c": `[1, {"d": null}]`, "e": "{}"}},
+		{description: "escaped key", data: `{"Ab": true}`, expect: map[string]string{"Ab": "true"}},
+		{description: "empty", data: `{}`, expect: map[string]string{}},
+		{description: "null", data: `null`, expect: map[string]string{}},
+		{description: "not object", data: `[1]`, expectErr: true},
+		{description: "trailing comma", data: `{"a":1,}`, expectErr: true},
+		{description: "trailing data", data: `{"a":1} x`, expectErr: true},
+		{description: "invalid number", data: `{"a":01}`, expectErr: true},
+		{description: "unterminated", data: `{"a":"x`, expectErr: true},
+	}
+	for _, testCase := range testCases {
+		actual := map[string]string{}
+		err := ScanJSONObject([]byte(testCase.data), func(key string, value []byte) error {
+			actual[key] = string(value)
+			return nil
+		})
+		if testCase.expectErr {
+			assert.True(t, errors.Is(err, ErrInvalidJSON), testCase.description)
+			continue
+		}
+		assert.Nil(t, err, testCase.description)
+		assert.EqualValues(t, testCase.expect, actual, testCase.description)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	text, err := DecodeJSONString([]byte(`"aé😀\n"`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "aé😀\n", text)
+
+	_, err = DecodeJSONString([]byte(`1`))
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+
+	i, err := DecodeJSONInt([]byte(`-128`), 8)
+	assert.Nil(t, err)
+	assert.EqualValues(t, -128, i)
+
+	_, err = DecodeJSONInt([]byte(`128`), 8)
+	assert.NotNil(t, err)
+
+	_, err = DecodeJSONInt([]byte(`1.5`), 64)
+	assert.NotNil(t, err)
+
+	u, err := DecodeJSONUint([]byte(`255`), 8)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 255, u)
+
+	f, err := DecodeJSONFloat([]byte(`1e-7`), 64)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1e-7, f)
+
+	b, err := DecodeJSONBool([]byte(`true`))
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	quoted, err := DecodeJSONQuoted([]byte(`"42"`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "42", string(quoted))
+
+	data, err := DecodeJSONBytes([]byte(`"aGk="`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "hi", string(data))
+
+	assert.EqualValues(t, 1, MatchJSONKey("NAME", "id", "name"))
+	assert.EqualValues(t, 2, MatchJSONKey("Name", "id", "name", "Name"))
+	assert.EqualValues(t, -1, MatchJSONKey("other", "id", "name"))
+}
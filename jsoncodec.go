@@ -0,0 +1,512 @@
+package xreflect
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const xreflectImportPath = "github.com/viant/xreflect"
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// jsonField represents JSON encoded field, promoted embedded struct fields are accessed by path
+type jsonField struct {
+	key       string
+	path      []reflect.StructField
+	index     []int
+	omitEmpty bool
+	quoted    bool
+	tagged    bool
+	types     []*fieldTypes //renders nested types of each path field
+}
+
+func (f *jsonField) rType() reflect.Type {
+	return f.path[len(f.path)-1].Type
+}
+
+// selector returns field selector for receiver
+func (f *jsonField) selector(receiver string) string {
+	builder := strings.Builder{}
+	builder.WriteString(receiver)
+	for _, field := range f.path {
+		builder.WriteString("." + field.Name)
+	}
+	return builder.String()
+}
+
+// embedded returns selectors and element type expressions of embedded struct pointers on field path
+func (f *jsonField) embedded(receiver string) ([]string, []string) {
+	var selectors []string
+	var exprs []string
+	selector := receiver
+	for i, field := range f.path[:len(f.path)-1] {
+		selector += "." + field.Name
+		if field.Type.Kind() == reflect.Ptr {
+			selectors = append(selectors, selector)
+			exprs = append(exprs, f.types[i].expr(field.Type.Elem()))
+		}
+	}
+	return selectors, exprs
+}
+
+// jsonFields returns struct JSON fields following encoding/json embedding and name dominance rules
+func jsonFields(structType reflect.Type, types func(index []int, aField *reflect.StructField) *fieldTypes) []*jsonField {
+	type level struct {
+		rType reflect.Type
+		path  []reflect.StructField
+		index []int
+		types []*fieldTypes
+	}
+	var fields []*jsonField
+	visited := map[reflect.Type]bool{}
+	next := []level{{rType: structType}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+		count := map[string]int{}
+		var candidates []*jsonField
+		for _, item := range current {
+			if visited[item.rType] {
+				continue
+			}
+			visited[item.rType] = true
+			for i := 0; i < item.rType.NumField(); i++ {
+				aField := item.rType.Field(i)
+				fieldType := aField.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+				if aField.Anonymous {
+					if aField.PkgPath != "" && fieldType.Kind() != reflect.Struct {
+						continue
+					}
+				} else if aField.PkgPath != "" {
+					continue
+				}
+				tag := aField.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := tag, ""
+				if index := strings.Index(tag, ","); index != -1 {
+					name, options = tag[:index], tag[index:]
+				}
+				if !isValidJSONKey(name) {
+					name = ""
+				}
+				path := append(append([]reflect.StructField{}, item.path...), aField)
+				index := append(append([]int{}, item.index...), i)
+				pathTypes := append(append([]*fieldTypes{}, item.types...), types(index, &aField))
+				if name == "" && aField.Anonymous && fieldType.Kind() == reflect.Struct {
+					next = append(next, level{rType: fieldType, path: path, index: index, types: pathTypes})
+					continue
+				}
+				field := &jsonField{key: firstNotEmptyString(name, aField.Name), path: path, index: index, tagged: name != "", types: pathTypes}
+				field.omitEmpty = strings.Contains(options+",", ",omitempty,")
+				if strings.Contains(options+",", ",string,") {
+					switch fieldType.Kind() {
+					case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64, reflect.String:
+						field.quoted = true
+					}
+				}
+				candidates = append(candidates, field)
+				count[field.key]++
+			}
+		}
+		taken := map[string]bool{}
+		for _, field := range fields {
+			taken[field.key] = true
+		}
+		for _, field := range candidates {
+			if taken[field.key] {
+				continue
+			}
+			taken[field.key] = true
+			if count[field.key] > 1 { //conflicting fields hide deeper ones even if none dominates
+				if field = dominantJSONField(field.key, candidates); field == nil {
+					continue
+				}
+			}
+			fields = append(fields, field)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		x, y := fields[i].index, fields[j].index
+		for k := 0; k < len(x) && k < len(y); k++ {
+			if x[k] != y[k] {
+				return x[k] < y[k]
+			}
+		}
+		return len(x) < len(y)
+	})
+	return fields
+}
+
+// dominantJSONField returns the only tagged field with supplied key, or nil if fields conflict
+func dominantJSONField(key string, candidates []*jsonField) *jsonField {
+	var dominant *jsonField
+	for _, candidate := range candidates {
+		if candidate.key != key || !candidate.tagged {
+			continue
+		}
+		if dominant != nil {
+			return nil
+		}
+		dominant = candidate
+	}
+	return dominant
+}
+
+func isValidJSONKey(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+		case !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c > 127):
+			return false
+		}
+	}
+	return true
+}
+
+// jsonCodec writes generated JSON encoding and decoding statements
+type jsonCodec struct {
+	pkg   string //xreflect helpers package qualifier
+	types *fieldTypes
+}
+
+func (c *jsonCodec) helper(name string) string {
+	return c.pkg + "." + name
+}
+
+// usesGenerated returns true if type has generated appendJSON and UnmarshalJSON methods
+func (c *jsonCodec) usesGenerated(rType reflect.Type) bool {
+	return c.types.generated(rType)
+}
+
+func isJSONMarshaler(rType reflect.Type) bool {
+	return rType.Implements(jsonMarshalerType)
+}
+
+func isJSONUnmarshaler(rType reflect.Type) bool {
+	return reflect.PtrTo(rType).Implements(jsonUnmarshalerType)
+}
+
+func isTextCodec(rType reflect.Type) bool {
+	return rType.Implements(textMarshalerType) || reflect.PtrTo(rType).Implements(textUnmarshalerType)
+}
+
+// emptyCondition returns omitempty check expression, empty string for values that are never empty
+func emptyCondition(expr string, rType reflect.Type) string {
+	switch rType.Kind() {
+	case reflect.Bool:
+		return "!" + expr
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return expr + " == 0"
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return "len(" + expr + ") == 0"
+	case reflect.Ptr, reflect.Interface:
+		return expr + " == nil"
+	}
+	return ""
+}
+
+// appendEncode writes statements appending JSON encoded expr to buf
+func (c *jsonCodec) appendEncode(builder *strings.Builder, expr string, rType reflect.Type, quoted bool, depth int) {
+	suffix := strconv.Itoa(depth)
+	onError := "; err != nil {\nreturn nil, err\n}\n"
+	if c.usesGenerated(rType) {
+		builder.WriteString("if buf, err = " + expr + ".appendJSON(buf)" + onError)
+		return
+	}
+	if isJSONMarshaler(rType) && rType.Kind() != reflect.Interface {
+		if rType.Kind() == reflect.Ptr {
+			builder.WriteString("if " + expr + " == nil {\nbuf = append(buf, \"null\"...)\n} else ")
+		}
+		builder.WriteString("if buf, err = " + c.helper("AppendJSONMarshaler") + "(buf, " + expr + ")" + onError)
+		return
+	}
+	if rType.Kind() != reflect.Ptr && isTextCodec(rType) {
+		c.appendEncodeValue(builder, expr, onError)
+		return
+	}
+	quote := ""
+	if quoted {
+		quote = "buf = append(buf, '\"')\n"
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		builder.WriteString("if " + expr + " == nil {\nbuf = append(buf, \"null\"...)\n} else {\n")
+		c.appendEncode(builder, "(*"+expr+")", rType.Elem(), quoted, depth+1)
+		builder.WriteString("}\n")
+	case reflect.String:
+		if quoted {
+			builder.WriteString("buf = " + c.helper("AppendJSONString") + "(buf, string(" + c.helper("AppendJSONString") + "(nil, string(" + expr + "))))\n")
+			return
+		}
+		builder.WriteString("buf = " + c.helper("AppendJSONString") + "(buf, string(" + expr + "))\n")
+	case reflect.Bool:
+		builder.WriteString(quote + "buf = " + c.types.qualify("strconv", "strconv") + ".AppendBool(buf, bool(" + expr + "))\n" + quote)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		builder.WriteString(quote + "buf = " + c.types.qualify("strconv", "strconv") + ".AppendInt(buf, int64(" + expr + "), 10)\n" + quote)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		builder.WriteString(quote + "buf = " + c.types.qualify("strconv", "strconv") + ".AppendUint(buf, uint64(" + expr + "), 10)\n" + quote)
+	case reflect.Float32, reflect.Float64:
+		builder.WriteString(quote + "if buf, err = " + c.helper("AppendJSONFloat") + "(buf, float64(" + expr + "), " + strconv.Itoa(rType.Bits()) + ")" + onError + quote)
+	case reflect.Slice:
+		builder.WriteString("if " + expr + " == nil {\nbuf = append(buf, \"null\"...)\n} else {\n")
+		if isJSONBytes(rType) {
+			builder.WriteString("buf = " + c.helper("AppendJSONBytes") + "(buf, []byte(" + expr + "))\n}\n")
+			return
+		}
+		c.appendEncodeElements(builder, expr, rType, suffix, depth)
+		builder.WriteString("}\n")
+	case reflect.Array:
+		c.appendEncodeElements(builder, expr, rType, suffix, depth)
+	case reflect.Map:
+		keyString, ok := c.keyString(rType.Key())
+		if !ok {
+			c.appendEncodeValue(builder, expr, onError)
+			return
+		}
+		keys, key, index := "keys"+suffix, "k"+suffix, "i"+suffix
+		builder.WriteString("if " + expr + " == nil {\nbuf = append(buf, \"null\"...)\n} else {\n")
+		builder.WriteString(keys + " := make([]" + c.types.expr(rType.Key()) + ", 0, len(" + expr + "))\n")
+		builder.WriteString("for " + key + " := range " + expr + " {\n" + keys + " = append(" + keys + ", " + key + ")\n}\n")
+		builder.WriteString(c.types.qualify("sort", "sort") + ".Slice(" + keys + ", func(x, y int) bool {\nreturn " +
+			keyString(keys+"[x]") + " < " + keyString(keys+"[y]") + "\n})\n")
+		builder.WriteString("buf = append(buf, '{')\n")
+		builder.WriteString("for " + index + ", " + key + " := range " + keys + " {\n")
+		builder.WriteString("if " + index + " > 0 {\nbuf = append(buf, ',')\n}\n")
+		builder.WriteString("buf = " + c.helper("AppendJSONString") + "(buf, " + keyString(key) + ")\n")
+		builder.WriteString("buf = append(buf, ':')\n")
+		c.appendEncode(builder, expr+"["+key+"]", rType.Elem(), false, depth+1)
+		builder.WriteString("}\nbuf = append(buf, '}')\n}\n")
+	default:
+		c.appendEncodeValue(builder, expr, onError)
+	}
+}
+
+func (c *jsonCodec) appendEncodeValue(builder *strings.Builder, expr string, onError string) {
+	builder.WriteString("if buf, err = " + c.helper("AppendJSONValue") + "(buf, " + expr + ")" + onError)
+}
+
+func (c *jsonCodec) appendEncodeElements(builder *strings.Builder, expr string, rType reflect.Type, suffix string, depth int) {
+	index := "i" + suffix
+	builder.WriteString("buf = append(buf, '[')\n")
+	builder.WriteString("for " + index + " := range " + expr + " {\n")
+	builder.WriteString("if " + index + " > 0 {\nbuf = append(buf, ',')\n}\n")
+	c.appendEncode(builder, expr+"["+index+"]", rType.Elem(), false, depth+1)
+	builder.WriteString("}\nbuf = append(buf, ']')\n")
+}
+
+// keyString returns map key to string conversion, false for key types encoded with encoding/json
+func (c *jsonCodec) keyString(rType reflect.Type) (func(expr string) string, bool) {
+	if rType.Kind() != reflect.String && isTextCodec(rType) {
+		return nil, false
+	}
+	switch rType.Kind() {
+	case reflect.String:
+		return func(expr string) string { return "string(" + expr + ")" }, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(expr string) string {
+			return c.types.qualify("strconv", "strconv") + ".FormatInt(int64(" + expr + "), 10)"
+		}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(expr string) string {
+			return c.types.qualify("strconv", "strconv") + ".FormatUint(uint64(" + expr + "), 10)"
+		}, true
+	}
+	return nil, false
+}
+
+// isJSONBytes returns true for byte slices encoded as base64 string
+func isJSONBytes(rType reflect.Type) bool {
+	elem := rType.Elem()
+	return elem.Kind() == reflect.Uint8 && !isJSONMarshaler(elem) && !isTextCodec(elem)
+}
+
+// appendDecode writes statements decoding JSON value into addressable dst, statements return error
+func (c *jsonCodec) appendDecode(builder *strings.Builder, dst string, value string, rType reflect.Type, quoted bool, depth int) {
+	suffix := strconv.Itoa(depth)
+	onError := "; err != nil {\nreturn err\n}\n"
+	if c.usesGenerated(rType) || (isJSONUnmarshaler(rType) && rType.Kind() != reflect.Interface && rType.Kind() != reflect.Ptr) {
+		builder.WriteString("if err := " + dst + ".UnmarshalJSON(" + value + ")" + onError)
+		return
+	}
+	if rType.Kind() != reflect.Ptr && isTextCodec(rType) {
+		c.appendDecodeValue(builder, dst, value, onError)
+		return
+	}
+	typeExpr := c.types.expr(rType)
+	isNull := c.helper("IsJSONNull") + "(" + value + ")"
+	if quoted && rType.Kind() != reflect.Ptr {
+		quotedValue := "q" + suffix
+		builder.WriteString(quotedValue + ", err := " + c.helper("DecodeJSONQuoted") + "(" + value + ")\nif err != nil {\nreturn err\n}\n")
+		value = quotedValue
+		isNull = c.helper("IsJSONNull") + "(" + value + ")"
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		builder.WriteString("if " + isNull + " {\n" + dst + " = nil\n} else {\n")
+		builder.WriteString("if " + dst + " == nil {\n" + dst + " = new(" + c.types.expr(rType.Elem()) + ")\n}\n")
+		c.appendDecode(builder, "(*"+dst+")", value, rType.Elem(), quoted, depth+1)
+		builder.WriteString("}\n")
+	case reflect.String:
+		c.appendDecodeLiteral(builder, dst, typeExpr, "DecodeJSONString("+value+")", isNull, suffix)
+	case reflect.Bool:
+		c.appendDecodeLiteral(builder, dst, typeExpr, "DecodeJSONBool("+value+")", isNull, suffix)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.appendDecodeLiteral(builder, dst, typeExpr, "DecodeJSONInt("+value+", "+strconv.Itoa(rType.Bits())+")", isNull, suffix)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c.appendDecodeLiteral(builder, dst, typeExpr, "DecodeJSONUint("+value+", "+strconv.Itoa(rType.Bits())+")", isNull, suffix)
+	case reflect.Float32, reflect.Float64:
+		c.appendDecodeLiteral(builder, dst, typeExpr, "DecodeJSONFloat("+value+", "+strconv.Itoa(rType.Bits())+")", isNull, suffix)
+	case reflect.Slice:
+		builder.WriteString("if " + isNull + " {\n" + dst + " = nil\n} else {\n")
+		if isJSONBytes(rType) {
+			decoded := "b" + suffix
+			builder.WriteString(decoded + ", err := " + c.helper("DecodeJSONBytes") + "(" + value + ")\nif err != nil {\nreturn err\n}\n")
+			builder.WriteString(dst + " = " + typeExpr + "(" + decoded + ")\n}\n")
+			return
+		}
+		items, item, elem := "items"+suffix, "item"+suffix, "elem"+suffix
+		builder.WriteString(items + " := make(" + typeExpr + ", 0)\n")
+		builder.WriteString("if err := " + c.helper("ScanJSONArray") + "(" + value + ", func(" + item + " []byte) error {\n")
+		builder.WriteString("var " + elem + " " + c.types.expr(rType.Elem()) + "\n")
+		c.appendDecode(builder, elem, item, rType.Elem(), false, depth+1)
+		builder.WriteString(items + " = append(" + items + ", " + elem + ")\nreturn nil\n})" + onError)
+		builder.WriteString(dst + " = " + items + "\n}\n")
+	case reflect.Array:
+		index, item := "i"+suffix, "item"+suffix
+		builder.WriteString("if !" + isNull + " {\n")
+		builder.WriteString(index + " := 0\n")
+		builder.WriteString("if err := " + c.helper("ScanJSONArray") + "(" + value + ", func(" + item + " []byte) error {\n")
+		builder.WriteString("if " + index + " < len(" + dst + ") {\n")
+		c.appendDecode(builder, dst+"["+index+"]", item, rType.Elem(), false, depth+1)
+		builder.WriteString("}\n" + index + "++\nreturn nil\n})" + onError)
+		builder.WriteString("for ; " + index + " < len(" + dst + "); " + index + "++ {\n")
+		builder.WriteString("var zero" + suffix + " " + c.types.expr(rType.Elem()) + "\n" + dst + "[" + index + "] = zero" + suffix + "\n}\n}\n")
+	case reflect.Map:
+		if _, ok := c.keyString(rType.Key()); !ok {
+			c.appendDecodeValue(builder, dst, value, onError)
+			return
+		}
+		key, item, elem := "key"+suffix, "item"+suffix, "elem"+suffix
+		builder.WriteString("if " + isNull + " {\n" + dst + " = nil\n} else {\n")
+		builder.WriteString("if " + dst + " == nil {\n" + dst + " = make(" + typeExpr + ")\n}\n")
+		builder.WriteString("if err := " + c.helper("ScanJSONObject") + "(" + value + ", func(" + key + " string, " + item + " []byte) error {\n")
+		mapKey := c.appendDecodeKey(builder, key, rType.Key(), suffix)
+		builder.WriteString("var " + elem + " " + c.types.expr(rType.Elem()) + "\n")
+		c.appendDecode(builder, elem, item, rType.Elem(), false, depth+1)
+		builder.WriteString(dst + "[" + mapKey + "] = " + elem + "\nreturn nil\n})" + onError + "}\n")
+	default:
+		c.appendDecodeValue(builder, dst, value, onError)
+	}
+}
+
+func (c *jsonCodec) appendDecodeValue(builder *strings.Builder, dst, value, onError string) {
+	builder.WriteString("if err := " + c.types.qualify("encoding/json", "json") + ".Unmarshal(" + value + ", &" + dst + ")" + onError)
+}
+
+func (c *jsonCodec) appendDecodeLiteral(builder *strings.Builder, dst, typeExpr, decode, isNull, suffix string) {
+	decoded := "v" + suffix
+	builder.WriteString("if !" + isNull + " {\n")
+	builder.WriteString(decoded + ", err := " + c.helper(decode) + "\nif err != nil {\nreturn err\n}\n")
+	builder.WriteString(dst + " = " + typeExpr + "(" + decoded + ")\n}\n")
+}
+
+// appendDecodeKey writes map key conversion and returns key expression
+func (c *jsonCodec) appendDecodeKey(builder *strings.Builder, key string, rType reflect.Type, suffix string) string {
+	typeExpr := c.types.expr(rType)
+	if rType.Kind() == reflect.String {
+		return typeExpr + "(" + key + ")"
+	}
+	parsed := "n" + suffix
+	parse := ".ParseInt("
+	if rType.Kind() != reflect.Int && rType.Kind() != reflect.Int8 && rType.Kind() != reflect.Int16 && rType.Kind() != reflect.Int32 && rType.Kind() != reflect.Int64 {
+		parse = ".ParseUint("
+	}
+	builder.WriteString(parsed + ", err := " + c.types.qualify("strconv", "strconv") + parse + key + ", 10, " + strconv.Itoa(rType.Bits()) + ")\nif err != nil {\nreturn err\n}\n")
+	return typeExpr + "(" + parsed + ")"
+}
+
+// appendJSONMethods writes MarshalJSON, appendJSON and UnmarshalJSON methods
+func appendJSONMethods(builder *strings.Builder, typeName string, fields []*jsonField, types *fieldTypes) {
+	receiver := receiverName(typeName)
+	codec := &jsonCodec{pkg: types.qualify(xreflectImportPath, "xreflect"), types: types}
+	builder.WriteString("\n\n// MarshalJSON encodes " + typeName + " as JSON without reflection\n")
+	builder.WriteString("func (" + receiver + " " + typeName + ") MarshalJSON() ([]byte, error) {\n")
+	builder.WriteString("return " + receiver + ".appendJSON(make([]byte, 0, 64))\n}")
+
+	builder.WriteString("\n\nfunc (" + receiver + " " + typeName + ") appendJSON(buf []byte) ([]byte, error) {\n")
+	builder.WriteString("var err error\nbuf = append(buf, '{')\nstart := len(buf)\n")
+	for _, field := range fields {
+		fieldCodec := codec.with(field.types[len(field.types)-1])
+		selectors, _ := field.embedded(receiver)
+		if len(selectors) > 0 {
+			builder.WriteString("if " + strings.Join(selectors, " != nil && ") + " != nil {\n")
+		}
+		selector := field.selector(receiver)
+		condition := ""
+		if field.omitEmpty {
+			condition = emptyCondition(selector, field.rType())
+		}
+		if condition != "" {
+			builder.WriteString("if !(" + condition + ") {\n")
+		}
+		key := AppendJSONString(nil, field.key)
+		builder.WriteString("if len(buf) > start {\nbuf = append(buf, ',')\n}\n")
+		builder.WriteString("buf = append(buf, " + strconv.Quote(string(key)+":") + "...)\n")
+		fieldCodec.appendEncode(builder, selector, field.rType(), field.quoted, 0)
+		if condition != "" {
+			builder.WriteString("}\n")
+		}
+		if len(selectors) > 0 {
+			builder.WriteString("}\n")
+		}
+	}
+	builder.WriteString("buf = append(buf, '}')\nreturn buf, err\n}")
+
+	builder.WriteString("\n\n// UnmarshalJSON decodes " + typeName + " from JSON without reflection\n")
+	builder.WriteString("func (" + receiver + " *" + typeName + ") UnmarshalJSON(data []byte) error {\n")
+	builder.WriteString("return " + codec.helper("ScanJSONObject") + "(data, func(key string, value []byte) error {\n")
+	if len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for _, field := range fields {
+			keys = append(keys, strconv.Quote(field.key))
+		}
+		builder.WriteString("switch " + codec.helper("MatchJSONKey") + "(key, " + strings.Join(keys, ", ") + ") {\n")
+		for i, field := range fields {
+			fieldCodec := codec.with(field.types[len(field.types)-1])
+			builder.WriteString("case " + strconv.Itoa(i) + ":\n")
+			selectors, exprs := field.embedded(receiver)
+			for j, selector := range selectors {
+				builder.WriteString("if " + selector + " == nil {\n" + selector + " = new(" + exprs[j] + ")\n}\n")
+			}
+			fieldCodec.appendDecode(builder, field.selector(receiver), "value", field.rType(), field.quoted, 0)
+		}
+		builder.WriteString("}\n")
+	}
+	builder.WriteString("return nil\n})\n}")
+}
+
+// with returns codec rendering field nested types
+func (c *jsonCodec) with(types *fieldTypes) *jsonCodec {
+	return &jsonCodec{pkg: c.pkg, types: types}
+}
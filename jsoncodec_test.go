@@ -0,0 +1,213 @@
+package xreflect
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type jsonCodecBase struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+	Name    string    `json:"name"`
+	Note    string
+}
+
+type JSONCodecAudit struct {
+	By   string `json:"by,omitempty"`
+	Note string
+}
+
+type jsonCodecRecord struct {
+	jsonCodecBase
+	*JSONCodecAudit
+	Name   string         `json:"name"`
+	Count  int64          `json:"count,string"`
+	Ratio  *float64       `json:"ratio,omitempty"`
+	Active bool           `json:",omitempty"`
+	Tags   []string       `json:"tags"`
+	Attrs  map[string]int `json:"attrs,omitempty"`
+	ByID   map[int]*struct {
+		Name string `json:"name"`
+	} `json:"byId"`
+	Raw    json.RawMessage `json:"raw,omitempty"`
+	Data   []byte          `json:"data"`
+	Grid   [2]uint8        `json:"grid"`
+	Any    interface{}     `json:"any"`
+	Skip   string          `json:"-"`
+	Small  float32
+	hidden int
+}
+
+func TestGenerateStruct_JSONCodec(t *testing.T) {
+	type Foo struct {
+		ID    int    `json:"id,omitempty"`
+		Name  string `json:"name"`
+		Count int    `json:",string"`
+	}
+	expect := `package xreflect
+
+import (
+	"github.com/viant/xreflect"
+	"strconv"
+)
+
+type Foo struct {
+	ID    int    ` + "`" + `json:"id,omitempty"` + "`" + `
+	Name  string ` + "`" + `json:"name"` + "`" + `
+	Count int    ` + "`" + `json:",string"` + "`" + `
+}
+`
+	actual := GenerateStruct("Foo", reflect.TypeOf(Foo{}), WithJSONCodec(true), WithPackage("xreflect"))
+	assert.True(t, strings.HasPrefix(actual, expect), actual)
+	assert.Contains(t, actual, "func (f Foo) MarshalJSON() ([]byte, error) {")
+	assert.Contains(t, actual, "func (f *Foo) UnmarshalJSON(data []byte) error {")
+	assert.Contains(t, actual, `switch xreflect.MatchJSONKey(key, "id", "name", "Count") {`)
+}
+
+// TestGenerateStruct_JSONCodec_RoundTrip runs generated codec and compares its output with encoding/json
+func TestGenerateStruct_JSONCodec_RoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping generated code build in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	samples := []string{
+		`{}`,
+		`{"id":1,"created":"2024-01-02T03:04:05Z","name":"rec","Note":"hidden","by":"admin","count":"42","ratio":0.5,"Active":true,"tags":["a","b"],"attrs":{"z":1,"a":2},"byId":{"10":{"name":"ten"},"2":null},"raw":{"x": [1, 2]},"data":"aGk=","grid":[1,2],"any":{"k":[true,null,1.5]},"Small":1e-7}`,
+		`{"ID":5,"NAME":"upper","By":"x","COUNT":"-7","tags":[],"attrs":{},"byId":{},"data":"","grid":[9]}`,
+		`{"name":null,"ratio":null,"tags":null,"attrs":null,"byId":null,"raw":null,"data":null,"grid":null,"any":null,"count":"null"}`,
+		`{"name":"esc \"q\" \\ é <b>&amp; \u2028 \ud83d\ude00 \n","Small":3.4028235e38,"ratio":1e21}`,
+		`{"grid":[1,2,3],"unknown":{"a":[1,{"b":null}]},"Skip":"ignored","hidden":1}`,
+		`{"id":"x"}`,
+		`{"count":42}`,
+		`{"grid":[256]}`,
+		`{"name":"x",}`,
+	}
+	wrapperSamples := []string{
+		`{}`,
+		`{"name":"w","otherId":"o","Code":3}`,
+		`{"Code":null,"otherId":null}`,
+		`{"otherId":1}`,
+	}
+	rType := reflect.TypeOf(jsonCodecRecord{})
+	inner := reflect.StructOf([]reflect.StructField{{Name: "Code", Type: IntType}})
+	other := reflect.StructOf([]reflect.StructField{
+		{Name: "ID", Type: StringType, Tag: `json:"otherId"`},
+		{Name: "Inner", Type: reflect.PtrTo(inner), Anonymous: true},
+	})
+	wrapperType := reflect.StructOf([]reflect.StructField{
+		{Name: "Name", Type: StringType, Tag: `json:"name"`},
+		{Name: "Other", Type: reflect.PtrTo(other), Anonymous: true},
+	})
+	var expect []string
+	for _, item := range []struct {
+		rType   reflect.Type
+		samples []string
+	}{{rType, samples}, {wrapperType, wrapperSamples}} {
+		for _, sample := range item.samples {
+			value := reflect.New(item.rType)
+			if err := json.Unmarshal([]byte(sample), value.Interface()); err != nil {
+				expect = append(expect, "error")
+				continue
+			}
+			data, err := json.Marshal(value.Interface())
+			assert.Nil(t, err, sample)
+			expect = append(expect, string(data))
+		}
+	}
+
+	source := GenerateStruct("Record", rType, WithJSONCodec(true), WithPackage("xreflect"),
+		WithTypes(NewType("jsonCodecBase", WithReflectType(reflect.TypeOf(jsonCodecBase{}))),
+			NewType("JSONCodecAudit", WithReflectType(reflect.TypeOf(JSONCodecAudit{})))))
+	wrapperSource := GenerateStruct("Wrapper", wrapperType, WithJSONCodec(true), WithPackage("xreflect"))
+	main := `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	model "harness/model"
+)
+
+type codec interface {
+	json.Marshaler
+	json.Unmarshaler
+}
+
+var samples = ` + fmt.Sprintf("%#v", samples) + `
+
+var wrapperSamples = ` + fmt.Sprintf("%#v", wrapperSamples) + `
+
+func roundTrip(value codec, sample string) {
+	if err := value.UnmarshalJSON([]byte(sample)); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	data, err := value.MarshalJSON()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+func main() {
+	for _, sample := range samples {
+		roundTrip(&model.Record{}, sample)
+	}
+	for _, sample := range wrapperSamples {
+		roundTrip(&model.Wrapper{}, sample)
+	}
+}
+`
+	moduleDir, err := os.Getwd()
+	if !assert.Nil(t, err) {
+		return
+	}
+	goSum, err := ioutil.ReadFile(filepath.Join(moduleDir, "go.sum"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	goMod := "module harness\n\ngo 1.16\n\nrequire github.com/viant/xreflect v0.0.0\n\nreplace github.com/viant/xreflect => " + moduleDir + "\n"
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           goMod,
+		"go.sum":           string(goSum),
+		"main.go":          main,
+		"model/model.go":   source,
+		"model/wrapper.go": wrapperSource,
+	}
+	for name, content := range files {
+		location := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(location), 0755))
+		assert.Nil(t, ioutil.WriteFile(location, []byte(content), 0644))
+	}
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+	output, err := cmd.CombinedOutput()
+	if !assert.Nil(t, err, string(output)+"\n"+source+"\n"+wrapperSource) {
+		return
+	}
+	actual := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if !assert.Equal(t, len(expect), len(actual), string(output)) {
+		return
+	}
+	for i, line := range actual {
+		if expect[i] == "error" {
+			assert.True(t, strings.HasPrefix(line, "error: "), line)
+			continue
+		}
+		assert.EqualValues(t, expect[i], line)
+	}
+}
@@ -0,0 +1,290 @@
+package xreflect
+
+import (
+	"context"
+	"golang.org/x/mod/modfile"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	//loadedPackage represents a package parsed by LoadPackages
+	loadedPackage struct {
+		location string
+		dirTypes *DirTypes
+		deps     []string
+	}
+
+	//packageLoader loads packages concurrently with bounded number of workers
+	packageLoader struct {
+		ctx         context.Context
+		options     []Option
+		registry    *Types
+		concurrency chan bool
+		wg          sync.WaitGroup
+		mux         sync.Mutex
+		loaded      map[string]*loadedPackage
+		err         error
+	}
+)
+
+// LoadPackages parses packages in supplied locations and their in-module dependencies concurrently,
+// packages are added to the registry once all types are resolved
+func (t *Types) LoadPackages(ctx context.Context, locations []string, opts ...Option) error {
+	o := options{}
+	o.Apply(opts...)
+	concurrency := o.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	staging := &Types{info: map[reflect.Type]*Type{}, parent: t}
+	loader := &packageLoader{
+		ctx:         ctx,
+		options:     append([]Option{WithTypeLookup(staging.Lookup)}, opts...),
+		registry:    staging,
+		concurrency: make(chan bool, concurrency),
+		loaded:      map[string]*loadedPackage{},
+	}
+	for _, location := range locations {
+		if absLocation, err := filepath.Abs(location); err == nil {
+			location = absLocation
+		}
+		loader.load(path.Clean(location))
+	}
+	loader.wg.Wait()
+	if loader.err != nil {
+		return loader.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := loader.resolve(); err != nil {
+		return err
+	}
+	return t.install(staging)
+}
+
+func (l *packageLoader) load(location string) {
+	l.mux.Lock()
+	if _, ok := l.loaded[location]; ok || l.err != nil {
+		l.mux.Unlock()
+		return
+	}
+	l.loaded[location] = nil
+	l.mux.Unlock()
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		select {
+		case <-l.ctx.Done():
+			l.setError(l.ctx.Err())
+			return
+		case l.concurrency <- true:
+		}
+		loaded, err := l.parse(location)
+		<-l.concurrency
+		if err != nil {
+			l.setError(err)
+			return
+		}
+		l.mux.Lock()
+		l.loaded[location] = loaded
+		l.mux.Unlock()
+		for _, dep := range loaded.deps {
+			l.load(dep)
+		}
+	}()
+}
+
+func (l *packageLoader) parse(location string) (*loadedPackage, error) {
+	module, moduleLocation := locateModule(location)
+	opts := l.options
+	if module != nil {
+		opts = append([]Option{WithModule(module, moduleLocation)}, opts...)
+	}
+	dirTypes, err := ParseTypes(location, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result := &loadedPackage{location: location, dirTypes: dirTypes}
+	if module == nil {
+		return result, nil
+	}
+	deps := map[string]bool{}
+	for _, imports := range dirTypes.imports {
+		for _, imp := range imports {
+			if imp.Module != module.Mod.Path && !strings.HasPrefix(imp.Module, module.Mod.Path+"/") {
+				continue
+			}
+			deps[path.Join(moduleLocation, imp.folder(module))] = true
+		}
+	}
+	for dep := range deps {
+		result.deps = append(result.deps, dep)
+	}
+	sort.Strings(result.deps)
+	return result, nil
+}
+
+func (l *packageLoader) setError(err error) {
+	l.mux.Lock()
+	if l.err == nil {
+		l.err = err
+	}
+	l.mux.Unlock()
+}
+
+// resolve resolves packages types in dependency order
+func (l *packageLoader) resolve() error {
+	var locations []string
+	for location := range l.loaded {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	visited := map[string]bool{}
+	var ordered []*loadedPackage
+	var visit func(location string)
+	visit = func(location string) {
+		loaded := l.loaded[location]
+		if visited[location] || loaded == nil {
+			return
+		}
+		visited[location] = true
+		for _, dep := range loaded.deps {
+			visit(dep)
+		}
+		ordered = append(ordered, loaded)
+	}
+	for _, location := range locations {
+		visit(location)
+	}
+	var errs Errors
+	for _, loaded := range ordered {
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+		errs.Append(l.registerPackage(loaded))
+	}
+	return errs.Err()
+}
+
+func (l *packageLoader) registerPackage(loaded *loadedPackage) error {
+	pkgName := loaded.dirTypes.PackagePath(loaded.location)
+	if pkgName == "" {
+		return nil
+	}
+	pkg, err := l.registry.importPackage(loaded.dirTypes.ModulePath, pkgName, loaded.location)
+	if err != nil {
+		return err
+	}
+	pkg.setDirType(loaded.dirTypes)
+	names := loaded.dirTypes.TypesNames()
+	sort.Strings(names)
+	var errs Errors
+	for _, name := range names {
+		aType := NewType(name, WithPackage(pkgName), WithImportPath(pkg.ImportPath), WithPackagePath(loaded.location), WithRegistry(l.registry))
+		errs.Append(l.registry.registerType(aType))
+	}
+	return errs.Err()
+}
+
+// install adds staging packages to the registry under single lock, nothing is installed on conflict
+func (t *Types) install(staging *Types) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
+	events, err := t.installPackages(staging)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		t.notify(event)
+	}
+	return nil
+}
+
+func (t *Types) installPackages(staging *Types) ([]*Event, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.info == nil {
+		t.info = map[reflect.Type]*Type{}
+	}
+	var errs Errors
+	var events []*Event
+	var installed []*Package
+	for key, pkg := range staging.packageMap() {
+		installed = append(installed, pkg)
+		name := pkg.Name
+		prev := t.packageMap()[key]
+		if prev == nil {
+			events = append(events, &Event{Kind: EventPackageCreated, Package: name})
+			for typeName, rType := range pkg.Types {
+				events = append(events, &Event{Kind: EventRegister, Package: name, Name: typeName, Type: rType})
+			}
+			continue
+		}
+		types := pkg.copyTypes()
+		prev.mux.RLock()
+		for typeName, rType := range pkg.Types {
+			previous := prev.Types[typeName]
+			resolved, err := t.policy.resolve(prev, typeName, previous, rType)
+			errs.Append(err)
+			if err != nil {
+				continue
+			}
+			types[typeName] = resolved
+			if resolved == previous {
+				continue
+			}
+			event := &Event{Kind: EventRegister, Package: name, Name: typeName, Type: resolved, Previous: previous}
+			if previous != nil {
+				event.Kind = EventReplace
+			}
+			events = append(events, event)
+		}
+		for typeName, rType := range prev.Types {
+			if _, has := types[typeName]; !has {
+				types[typeName] = rType
+			}
+		}
+		pkg.Final = prev.Final
+		prev.mux.RUnlock()
+		pkg.setTypes(types)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	t.storePackages(installed...)
+	for rType, info := range staging.info {
+		if info.Registry == staging {
+			info.Registry = t
+		}
+		t.info[rType] = info
+	}
+	atomic.AddUint64(&t.generation, 1)
+	return events, nil
+}
+
+// locateModule returns module enclosing location and module location
+func locateModule(location string) (*modfile.Module, string) {
+	for candidate := location; ; {
+		if data, err := os.ReadFile(path.Join(candidate, "go.mod")); err == nil {
+			if aFile, err := modfile.Parse("", data, nil); err == nil && aFile.Module != nil {
+				return aFile.Module, candidate
+			}
+			return nil, ""
+		}
+		parent := path.Dir(candidate)
+		if parent == candidate {
+			return nil, ""
+		}
+		candidate = parent
+	}
+}
@@ -0,0 +1,218 @@
+package xreflect
+
+import (
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+type (
+	//MergeReport represents merge outcome, type names are package qualified
+	MergeReport struct {
+		Added     []string
+		Replaced  []string
+		Kept      []string
+		Packages  []string //packages created in the destination registry
+		Conflicts []*ConflictError
+	}
+
+	mergeEntry struct {
+		name     string
+		rType    reflect.Type
+		previous reflect.Type
+	}
+
+	mergePlan struct {
+		source *Package
+		dest   *Package
+		types  []*mergeEntry
+	}
+)
+
+// HasConflicts returns true if merge reported conflicts
+func (r *MergeReport) HasConflicts() bool {
+	return len(r.Conflicts) > 0
+}
+
+// Merge merges packages, types, methods, sources, aliases and type info from supplied registry,
+// conflicts are resolved with registry override policy unless WithOverridePolicy option is supplied,
+// nothing is merged if any conflict can not be resolved
+func (t *Types) Merge(from *Types, opts ...Option) (*MergeReport, error) {
+	report := &MergeReport{}
+	if from == nil || from == t {
+		return report, nil
+	}
+	if err := t.checkWritable(); err != nil {
+		return report, err
+	}
+	o := options{}
+	o.overridePolicy = t.policy
+	o.Apply(opts...)
+	policy := o.overridePolicy
+
+	sourceIndex := from.packageIndex()
+	keys := make([]string, 0, len(sourceIndex.packages))
+	for key := range sourceIndex.packages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var plans []*mergePlan
+	var errs Errors
+	for _, key := range keys {
+		source := sourceIndex.packages[key]
+		plan := &mergePlan{source: source, dest: t.packageMap()[key]}
+		sourceTypes := source.typeMap()
+		typeNames := make([]string, 0, len(sourceTypes))
+		for name := range sourceTypes {
+			typeNames = append(typeNames, name)
+		}
+		sort.Strings(typeNames)
+		for _, name := range typeNames {
+			candidate := sourceTypes[name]
+			var existing reflect.Type
+			if plan.dest != nil {
+				existing = plan.dest.typeMap()[name]
+			}
+			qualified := source.Name + "." + name
+			resolved := candidate
+			if plan.dest != nil {
+				var err error
+				if resolved, err = policy.resolve(plan.dest, name, existing, candidate); err != nil {
+					if conflict, ok := err.(*ConflictError); ok {
+						report.Conflicts = append(report.Conflicts, conflict)
+					}
+					errs.Append(err)
+					continue
+				}
+			}
+			switch {
+			case resolved == existing && existing != candidate:
+				report.Kept = append(report.Kept, qualified)
+			case existing == nil:
+				report.Added = append(report.Added, qualified)
+				plan.types = append(plan.types, &mergeEntry{name: name, rType: resolved})
+			case resolved != existing:
+				report.Replaced = append(report.Replaced, qualified)
+				plan.types = append(plan.types, &mergeEntry{name: name, rType: resolved, previous: existing})
+			}
+		}
+		plans = append(plans, plan)
+	}
+	if len(errs) > 0 {
+		return report, errs
+	}
+
+	for _, plan := range plans {
+		if plan.dest == nil {
+			report.Packages = append(report.Packages, plan.source.key())
+		}
+		var err error
+		if plan.dest, err = t.importPackage(plan.source.ImportPath, plan.source.Name, plan.source.Path); err != nil {
+			return report, err
+		}
+		plan.apply()
+		t.mergeInfo(from, plan)
+	}
+	t.mux.Lock()
+	t.updatePackages(func(index *packageIndex) {
+		for alias, key := range sourceIndex.aliases {
+			if _, ok := index.aliases[alias]; !ok {
+				index.aliases[alias] = key
+			}
+		}
+		for location, key := range sourceIndex.locations {
+			if _, ok := index.locations[location]; !ok {
+				index.locations[location] = key
+			}
+		}
+	})
+	t.mux.Unlock()
+	atomic.AddUint64(&t.generation, 1)
+	for _, plan := range plans {
+		for _, entry := range plan.types {
+			t.notifyChange(plan.dest.Name, entry.name, entry.previous, entry.rType)
+		}
+	}
+	return report, nil
+}
+
+// apply copies planned types with their methods, source handles and constants to the destination package
+func (p *mergePlan) apply() {
+	source, dest := p.source, p.dest
+	source.mux.RLock()
+	methods := make(map[string][]reflect.Method, len(p.types))
+	for _, entry := range p.types {
+		if typeMethods, ok := source.methods[entry.name]; ok {
+			methods[entry.name] = typeMethods
+		}
+	}
+	dirType, final, constants, imports := source.dirType, source.Final, source.constants, source.imports
+	source.mux.RUnlock()
+
+	dest.mux.Lock()
+	defer dest.mux.Unlock()
+	if len(p.types) > 0 {
+		types := dest.copyTypes()
+		for _, entry := range p.types {
+			types[entry.name] = entry.rType
+			delete(dest.methods, entry.name)
+			if typeMethods, ok := methods[entry.name]; ok {
+				dest.methods[entry.name] = typeMethods
+			}
+		}
+		dest.setTypes(types)
+	}
+	if dest.dirType == nil {
+		dest.dirType = dirType
+	}
+	if dest.Path == "" {
+		dest.Path = source.Path
+	}
+	for k, v := range constants {
+		if dest.constants == nil {
+			dest.constants = map[string]string{}
+		}
+		if _, ok := dest.constants[k]; !ok {
+			dest.constants[k] = v
+		}
+	}
+	for k, v := range imports {
+		if dest.imports == nil {
+			dest.imports = map[string][]string{}
+		}
+		if _, ok := dest.imports[k]; !ok {
+			dest.imports[k] = v
+		}
+	}
+	dest.Final = dest.Final || final
+}
+
+// mergeInfo copies source type info of merged types, info of replaced types is removed
+func (t *Types) mergeInfo(from *Types, plan *mergePlan) {
+	from.mux.RLock()
+	infos := make([]*Type, len(plan.types))
+	for i, entry := range plan.types {
+		infos[i] = from.info[entry.rType]
+	}
+	from.mux.RUnlock()
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.info == nil {
+		t.info = map[reflect.Type]*Type{}
+	}
+	for i, entry := range plan.types {
+		if entry.previous != nil {
+			if info, ok := t.info[entry.previous]; ok && info.Package == plan.dest.Name && rawName(info.Name) == entry.name {
+				delete(t.info, entry.previous)
+			}
+		}
+		info := &Type{Name: entry.name, Package: plan.dest.Name, ImportPath: plan.dest.ImportPath, PackagePath: plan.dest.Path}
+		if source := infos[i]; source != nil {
+			clone := *source
+			info = &clone
+		}
+		info.Type = entry.rType
+		info.Registry = t
+		t.info[entry.rType] = info
+	}
+}
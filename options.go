@@ -18,6 +18,9 @@ type (
 		onStruct       func(spec *ast.TypeSpec, aStruct *ast.StructType, imports GoImports) error
 		onLookup       func(packagePath, pkg, typeName string, rType reflect.Type)
 		GoImports      GoImports
+		breakCycles    bool
+		lenient        bool
+		collectErrors  bool
 	}
 
 	generateOption struct {
@@ -31,12 +34,30 @@ type (
 		skipFieldType func(field *reflect.StructField) bool
 		//function to customize field (tag), corresponding type name and generated field documentation
 		onStructField func(field *reflect.StructField, tag, typeName, documentation *string)
+		//function to return generated type field doc, i.e. DirTypes.FieldDoc
+		fieldDoc func(typeName, fieldName string) string
+		//function to return generated package file name for a type
+		fileName func(aType *Type) string
+		getters  bool
+		setters  bool
+		presence bool
+		//flag to generate NewX constructors with Init applying default tag values
+		constructors bool
+		deepCopy     bool
+		equal        bool
+		jsonCodec    bool
+		//flag to start generated source with GeneratedHeader
+		header          bool
+		buildConstraint string
 	}
 
 	registryOptions struct {
 		withTypes          []*Type
 		withReflectTypes   []reflect.Type
 		withReflectPackage string
+		concurrency        int
+		cacheDir           string
+		overridePolicy     OverridePolicy
 	}
 
 	options struct {
@@ -133,6 +154,13 @@ func WithOnStructField(fn func(field *reflect.StructField, tagName, typeName, do
 	}
 }
 
+// WithFieldDoc returns option to document generated fields with source comments, i.e. WithFieldDoc(dirTypes.FieldDoc)
+func WithFieldDoc(fn func(typeName, fieldName string) string) Option {
+	return func(o *options) {
+		o.fieldDoc = fn
+	}
+}
+
 // WithSnippetBefore creates snippet option
 func WithSnippetBefore(snippet string) Option {
 	return func(o *options) {
@@ -152,6 +180,20 @@ func WithPackagePath(pkgPath string) Option {
 	}
 }
 
+// WithImportPath creates package import path option
+func WithImportPath(importPath string) Option {
+	return func(t *options) {
+		t.ImportPath = importPath
+	}
+}
+
+// WithTypeDoc returns option to set type documentation
+func WithTypeDoc(doc string) Option {
+	return func(t *options) {
+		t.Doc = doc
+	}
+}
+
 func WithTypeDefinition(definition string) Option {
 	return func(t *options) {
 		t.Definition = definition
@@ -195,6 +237,27 @@ func WithReflectPackage(pkg string) Option {
 	}
 }
 
+// WithConcurrency returns option to limit number of packages parsed concurrently
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithCacheDir returns option to cache parsed package types in supplied directory
+func WithCacheDir(dir string) Option {
+	return func(o *options) {
+		o.cacheDir = dir
+	}
+}
+
+// WithOverridePolicy returns option to control registration of a type under already taken name
+func WithOverridePolicy(policy OverridePolicy) Option {
+	return func(o *options) {
+		o.overridePolicy = policy
+	}
+}
+
 // WithPackageTypes return option with package types
 func WithPackageTypes(pkgTypes ...*Type) Option {
 	return func(o *options) {
@@ -231,9 +294,102 @@ func WithSkipFieldType(fn func(field *reflect.StructField) bool) Option {
 	}
 }
 
-func withOptions(opt *options) Option {
+// WithFileName returns option to customize generated package file name, types with the same file name are grouped
+func WithFileName(fn func(aType *Type) string) Option {
+	return func(o *options) {
+		o.fileName = fn
+	}
+}
+
+// WithGetters returns option to generate nil safe GetX field getters
+func WithGetters(flag bool) Option {
+	return func(o *options) {
+		o.getters = flag
+	}
+}
+
+// WithSetters returns option to generate SetX field setters
+func WithSetters(flag bool) Option {
+	return func(o *options) {
+		o.setters = flag
+	}
+}
+
+// WithPresence returns option to generate presence bitmask with HasX markers, presence implies SetX setters marking fields as set
+func WithPresence(flag bool) Option {
+	return func(o *options) {
+		o.presence = flag
+	}
+}
+
+// WithConstructors returns option to generate NewX constructors and Init methods applying default tag values
+func WithConstructors(flag bool) Option {
+	return func(o *options) {
+		o.constructors = flag
+	}
+}
+
+// WithDeepCopy returns option to generate Clone and DeepCopyInto methods
+func WithDeepCopy(flag bool) Option {
+	return func(o *options) {
+		o.deepCopy = flag
+	}
+}
+
+// WithEqual returns option to generate Equal method
+func WithEqual(flag bool) Option {
+	return func(o *options) {
+		o.equal = flag
+	}
+}
+
+// WithJSONCodec returns option to generate reflection free MarshalJSON and UnmarshalJSON methods honouring json tags
+func WithJSONCodec(flag bool) Option {
+	return func(o *options) {
+		o.jsonCodec = flag
+	}
+}
+
+// WithGeneratedHeader returns option to start GenerateStruct output with GeneratedHeader
+func WithGeneratedHeader(flag bool) Option {
+	return func(o *options) {
+		o.header = flag
+	}
+}
+
+// WithBuildConstraint returns option to add //go:build constraint i.e. "linux && !race" to generated source
+func WithBuildConstraint(expr string) Option {
+	return func(o *options) {
+		o.buildConstraint = expr
+	}
+}
+
+// WithBreakCycles returns option to break recursive type references at pointer, slice or map with an interface{} placeholder
+func WithBreakCycles(flag bool) Option {
+	return func(o *options) {
+		o.breakCycles = flag
+	}
+}
+
+// WithLenient returns option to substitute unresolved types with an interface{} placeholder instead of failing
+func WithLenient(flag bool) Option {
+	return func(o *options) {
+		o.lenient = flag
+	}
+}
+
+// WithCollectErrors returns option to resolve all types with ParseTypes, failures are reported as Errors
+func WithCollectErrors(flag bool) Option {
+	return func(o *options) {
+		o.collectErrors = flag
+	}
+}
+
+// withDependencyOptions returns options for lazily parsed dependency package, errors are only collected for requested package
+func withDependencyOptions(opt *options) Option {
 	return func(o *options) {
 		*o = *opt
+		o.collectErrors = false
 	}
 }
 
@@ -2,6 +2,7 @@ package xreflect
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -27,9 +29,25 @@ func ParseTypes(path string, options ...Option) (*DirTypes, error) {
 	}
 
 	dirTypes.ModulePath = detectModulePath(path)
+	if dirTypes.collectErrors {
+		return dirTypes, dirTypes.resolveTypes()
+	}
 	return dirTypes, nil
 }
 
+// resolveTypes resolves all indexed types, returns all failures as Errors
+func (t *DirTypes) resolveTypes() error {
+	names := t.TypesNames()
+	sort.Strings(names)
+	var errs Errors
+	for _, name := range names {
+		if _, err := t.Type(name); err != nil {
+			errs.Append(err)
+		}
+	}
+	return errs.Err()
+}
+
 func detectModulePath(aPath string) string {
 	parts := strings.Split(aPath, "/")
 	var index int
@@ -71,8 +89,12 @@ func (t *DirTypes) indexPackage(aPackage *ast.Package) error {
 			if !ok {
 				continue
 			}
+			declDoc := genDecl.Doc
+			if len(genDecl.Specs) > 1 { //grouped declaration doc does not describe individual types
+				declDoc = nil
+			}
 			for _, spec := range genDecl.Specs {
-				t.indexTypeSpec(path, aPackage.Name, spec)
+				t.indexTypeSpec(path, aPackage.Name, spec, declDoc)
 			}
 		}
 	}
@@ -117,12 +139,12 @@ func asFuncDecl(spec interface{}) (*ast.FuncDecl, bool) {
 	return decl, ok
 }
 
-func (t *DirTypes) indexTypeSpec(path string, pkg string, spec ast.Spec) {
+func (t *DirTypes) indexTypeSpec(path string, pkg string, spec ast.Spec, declDoc *ast.CommentGroup) {
 	typeSpec, ok := asTypeSpec(spec)
 	if !ok {
 		return
 	}
-	t.registerTypeSpec(path, pkg, typeSpec)
+	t.registerTypeSpec(path, pkg, typeSpec, declDoc)
 }
 
 func Parse(dataType string, opts ...Option) (reflect.Type, error) {
@@ -142,7 +164,7 @@ func Parse(dataType string, opts ...Option) (reflect.Type, error) {
 		return nil, err
 	}
 	types := NewDirTypes("")
-	types.Apply(WithTypeLookup(lookup), WithPackage(o.Package), WithRegistry(o.Registry), WithModule(o.module, o.moduleLocation))
+	types.Apply(WithTypeLookup(lookup), WithPackage(o.Package), WithRegistry(o.Registry), WithModule(o.module, o.moduleLocation), WithLenient(o.lenient))
 	typeSpec := &TypeSpec{DirTypes: types}
 	pkgPath := ""
 	rType, err := typeSpec.matchType(types.Package, &pkgPath, nil, expr, o.GoImports)
@@ -160,7 +182,9 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 	}
 	switch actual := expr.(type) {
 	case *ast.StarExpr:
+		t.edges++
 		rType, err := t.matchType(pkg, pkgPath, spec, actual.X, imps)
+		t.edges--
 		if err != nil {
 			return nil, err
 		}
@@ -183,6 +207,7 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 				}
 			}
 			prevTypeName := ""
+			placeholder := ""
 			tag := ""
 			if field.Tag != nil {
 				unquote, err := strconv.Unquote(field.Tag.Value)
@@ -190,9 +215,15 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 					return nil, err
 				}
 				tag = unquote
-				tag, prevTypeName = RemoveTag(tag, TagTypeName)
+				if placeholder = reflect.StructTag(tag).Get(TagPlaceholder); placeholder != "" {
+					prevTypeName = reflect.StructTag(tag).Get(TagTypeName)
+					tag, _ = RemoveTag(tag, TagPlaceholder)
+					tag, _ = RemoveTag(tag, TagTypeName)
+				} else {
+					tag, prevTypeName = RemoveTag(tag, TagTypeName)
+				}
 			}
-
+			placeholders := len(t.placeholders)
 			fieldType, err := t.matchType(pkg, pkgPath, spec, field.Type, imps)
 			if err != nil {
 				return nil, err
@@ -200,12 +231,17 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 			n := Node{Node: field.Type}
 
 			typeName, _ := n.Stringify()
-			if prevTypeName != "" {
+			if placeholder != "" && prevTypeName != "" { //preserve intended type name of a placeholder field
+				tag += " " + TagTypeName + `:"` + prevTypeName + `" ` + TagPlaceholder + `:"` + placeholder + `"`
+			} else if placeholderTag := t.placeholderTag(placeholders, fieldName(field), fieldType); placeholderTag != "" {
+				tag += " " + placeholderTag
+			} else if prevTypeName != "" {
 				if typeName == "" {
 					typeName = prevTypeName
 				}
 				tag += " " + TagTypeName + `:"` + componentType(typeName) + `"`
 			}
+			anonymous := strings.Contains(tag, "anonymous")
 
 			for _, name := range field.Names {
 				if seen[name.Name] {
@@ -218,7 +254,7 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 					Type:    fieldType,
 					PkgPath: PkgPath(name.Name, pkg),
 				}
-				structField.Anonymous = name.Name == fieldType.Name() && strings.Contains(string(structField.Tag), "anonymous")
+				structField.Anonymous = name.Name == fieldType.Name() && anonymous
 				rFields = append(rFields, structField)
 			}
 			if len(field.Names) == 0 {
@@ -257,20 +293,28 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 					rType, err = t.lookup("", pkgPath, actual.Sel.Name)
 				}
 				if err != nil {
-					return nil, err
+					if t.lenient {
+						return t.addPlaceholder(&Placeholder{Target: packageIdent.Name + "." + actual.Sel.Name, PackagePath: imps.Lookup(packageIdent.Name), Reason: PlaceholderUnresolved}), nil
+					}
+					return nil, selectorError(err, packageIdent.Name, actual.Sel.Name, imps)
 				}
 			}
 			return rType, nil
 		} else {
 			rType, err := t.lookup("", "", actual.Sel.Name)
 			if err != nil {
+				if t.lenient {
+					return t.addPlaceholder(&Placeholder{Target: actual.Sel.Name, Reason: PlaceholderUnresolved}), nil
+				}
 				return nil, err
 			}
 			return rType, nil
 		}
 
 	case *ast.ArrayType:
+		t.edges++
 		rType, err := t.matchType(pkg, pkgPath, spec, actual.Elt, imps)
+		t.edges--
 		if err != nil {
 			return nil, err
 		}
@@ -280,7 +324,9 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 		if err != nil {
 			return nil, err
 		}
+		t.edges++
 		valueType, err := t.matchType(pkg, pkgPath, spec, actual.Value, imps)
+		t.edges--
 		if err != nil {
 			return nil, err
 		}
@@ -331,6 +377,9 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 			}
 			rType, err := t.lookup("", "", actual.Name)
 			if err != nil {
+				if t.lenient && !t.inProgress[actual.Name] {
+					return t.addPlaceholder(&Placeholder{Target: actual.Name, Reason: PlaceholderUnresolved}), nil
+				}
 				return nil, err
 			}
 			return rType, nil
@@ -338,7 +387,7 @@ func (t *TypeSpec) matchType(pkg string, pkgPath *string, spec *ast.TypeSpec, ex
 
 	}
 
-	return nil, fmt.Errorf("unsupported %T, %v", expr, expr)
+	return nil, newTypeError(ErrUnsupportedNode, fmt.Sprintf("%T", expr), pkg, "")
 }
 
 var JSONRawMessageType = reflect.TypeOf(json.RawMessage{})
@@ -379,6 +428,38 @@ func PkgPath(fieldName string, pkgPath string) (fieldPath string) {
 	return fieldPath
 }
 
+// selectorError qualifies type error with selector package
+func selectorError(err error, pkg, name string, imps GoImports) error {
+	typeErr := &TypeError{}
+	if !errors.As(err, &typeErr) || typeErr.Package != "" || !errors.Is(typeErr.Err, ErrTypeNotFound) {
+		return err
+	}
+	pkgPath := imps.Lookup(pkg)
+	if pkgPath == "" {
+		return newTypeError(ErrPackageNotFound, name, pkg, "")
+	}
+	return newTypeError(ErrTypeNotFound, name, pkg, pkgPath)
+}
+
+// fieldDoc returns field doc or line comment text
+func fieldDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	return ""
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	name, _ := Node{field.Type}.Stringify()
+	return rawName(name)
+}
+
 func asTypeSpec(spec ast.Spec) (*ast.TypeSpec, bool) {
 	result, ok := spec.(*ast.TypeSpec)
 	return result, ok
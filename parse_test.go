@@ -1,11 +1,16 @@
 package xreflect
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/assertly"
 	"go/ast"
 	"go/parser"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -419,3 +424,204 @@ func TestPackages(t *testing.T) {
 		assertly.AssertValues(t, testCase.packages, methods, testCase.description)
 	}
 }
+
+func TestParseTypes_BreakCycles(t *testing.T) {
+	testCases := []struct {
+		description  string
+		name         string
+		options      []Option
+		expectErr    bool
+		expectTarget string
+		expected     string
+	}{
+		{
+			description: "self-referencing type without cycle breaking",
+			name:        "Node",
+			expectErr:   true,
+		},
+		{
+			description:  "self-referencing type",
+			name:         "Node",
+			options:      []Option{WithBreakCycles(true)},
+			expectTarget: "Node",
+			expected:     "package generated\n\ntype Node struct {\n\tID       int\n\tChildren []*Node\n\tIndex    map[string]*Node\n}\n",
+		},
+		{
+			description:  "mutually recursive types",
+			name:         "Parent",
+			options:      []Option{WithBreakCycles(true)},
+			expectTarget: "Parent",
+			expected:     "package generated\n\ntype Parent struct {\n\tName  string\n\tChild *Child\n}\n\ntype Child struct {\n\tName   string\n\tParent *Parent\n}\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		types, err := ParseTypes("./internal/testdata", testCase.options...)
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		rType, err := types.Type(testCase.name)
+		if testCase.expectErr {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		placeholders := types.Placeholders()
+		if assert.NotEmpty(t, placeholders, testCase.description) {
+			assert.Equal(t, testCase.expectTarget, placeholders[0].Target, testCase.description)
+			assert.Equal(t, PlaceholderCycle, placeholders[0].Reason, testCase.description)
+		}
+		assert.Equal(t, testCase.expected, GenerateStruct(testCase.name, rType), testCase.description)
+	}
+}
+
+func TestParseTypes_BreakCycles_Error(t *testing.T) {
+	location := t.TempDir()
+	source := "package model\n\ntype Node struct {\n\tParent *Node\n\tOwner  Missing\n}\n"
+	if !assert.Nil(t, os.WriteFile(filepath.Join(location, "node.go"), []byte(source), 0644)) {
+		return
+	}
+	types, err := ParseTypes(location, WithBreakCycles(true))
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = types.Type("Node")
+	assert.True(t, errors.Is(err, ErrTypeNotFound), err)
+	assert.Empty(t, types.Placeholders())
+}
+
+func TestParseTypes_Lenient(t *testing.T) {
+	testCases := []struct {
+		description      string
+		options          []Option
+		expectErr        bool
+		expectUnresolved []*Placeholder
+	}{
+		{
+			description: "strict resolution",
+			expectErr:   true,
+		},
+		{
+			description: "lenient resolution",
+			options:     []Option{WithLenient(true)},
+			expectUnresolved: []*Placeholder{
+				{Owner: "Order", Field: "Customer", Target: "crm.Customer", PackagePath: "github.com/acme/crm", Reason: PlaceholderUnresolved},
+				{Owner: "Order", Field: "Audit", Target: "Audit", Reason: PlaceholderUnresolved},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		types, err := ParseTypes("./internal/testdata/lenient", testCase.options...)
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		rType, err := types.Type("Order")
+		if testCase.expectErr {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expectUnresolved, types.Unresolved(), testCase.description)
+		field, _ := rType.FieldByName("Customer")
+		assert.Equal(t, "crm.Customer", field.Tag.Get(TagTypeName), testCase.description)
+		field, _ = rType.FieldByName("Items")
+		assert.Equal(t, reflect.Slice, field.Type.Kind(), testCase.description)
+	}
+}
+
+func TestParseTypes_CollectErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		location    string
+		expectErr   error
+		expectCount int
+		expectType  *TypeError
+	}{
+		{
+			description: "unresolved types",
+			location:    "./internal/testdata/lenient",
+			expectErr:   ErrTypeNotFound,
+			expectCount: 1,
+			expectType:  &TypeError{Err: ErrTypeNotFound, Name: "Customer", Package: "crm", PackagePath: "github.com/acme/crm"},
+		},
+		{
+			description: "recursive types",
+			location:    "./internal/testdata",
+			expectErr:   ErrCycle,
+			expectCount: 3,
+		},
+	}
+
+	for _, testCase := range testCases {
+		types, err := ParseTypes(testCase.location, WithCollectErrors(true))
+		assert.NotNil(t, types, testCase.description)
+		if !assert.NotNil(t, err, testCase.description) {
+			continue
+		}
+		assert.True(t, errors.Is(err, testCase.expectErr), testCase.description)
+		errs, ok := err.(Errors)
+		if assert.True(t, ok, testCase.description) {
+			assert.Equal(t, testCase.expectCount, len(errs), testCase.description)
+		}
+		if testCase.expectType != nil {
+			typeErr := &TypeError{}
+			if assert.True(t, errors.As(err, &typeErr), testCase.description) {
+				assert.Equal(t, testCase.expectType, typeErr, testCase.description)
+			}
+		}
+	}
+}
+
+func TestDirTypes_Query(t *testing.T) {
+	testCases := []struct {
+		description string
+		predicates  []Predicate
+		expect      []string
+		expectField string
+	}{
+		{description: "field tag value", predicates: []Predicate{HasFieldTagValue("xdatly", "kind:data_view")}, expect: []string{"query.Customer", "query.Order"}, expectField: "Items"},
+		{description: "field tag", predicates: []Predicate{HasFieldTag("json")}, expect: []string{"query.Customer"}},
+		{description: "embeds", predicates: []Predicate{Embeds("Audit")}, expect: []string{"query.Order"}},
+		{description: "method", predicates: []Predicate{HasMethod("Validate")}, expect: []string{"query.Order"}},
+		{description: "kind", predicates: []Predicate{HasKind(reflect.Int)}, expect: []string{"query.Status"}},
+		{description: "struct with fields", predicates: []Predicate{HasKind(reflect.Struct), NumFields(2)}, expect: []string{"query.Item"}},
+		{description: "negation", predicates: []Predicate{HasKind(reflect.Struct), Not(Or(HasFieldTag("xdatly"), Embeds("Audit")))}, expect: []string{"query.Audit", "query.Item"}},
+	}
+	dirTypes, err := ParseTypes("./internal/testdata/query")
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, testCase := range testCases {
+		results := dirTypes.Query(testCase.predicates...)
+		var actual []string
+		for _, result := range results {
+			actual = append(actual, result.TypeName())
+			assert.NotEmpty(t, result.Definition, testCase.description)
+		}
+		assert.Equal(t, testCase.expect, actual, testCase.description)
+		if testCase.expectField != "" && assert.NotEmpty(t, results, testCase.description) {
+			assert.Equal(t, testCase.expectField, results[len(results)-1].Fields[0].Name, testCase.description)
+		}
+	}
+}
+
+func TestParseTypes_CollectErrors_Dependency(t *testing.T) {
+	location, err := filepath.Abs(".")
+	if !assert.Nil(t, err) {
+		return
+	}
+	module := &modfile.Module{Mod: module.Version{Path: "github.com/viant/xreflect"}}
+	types, err := ParseTypes("./internal/testdata/collect/api", WithCollectErrors(true), WithModule(module, location))
+	if !assert.Nil(t, err, "broken dependency type should not be collected") {
+		return
+	}
+	rType, err := types.Type("Response")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "struct { Status string; Order *struct { ID int; Name string } }", rType.String())
+	}
+}
@@ -0,0 +1,77 @@
+package xreflect
+
+import (
+	"reflect"
+)
+
+const (
+	//PlaceholderCycle placeholder reason for a recursive type reference
+	PlaceholderCycle = "cycle"
+	//PlaceholderUnresolved placeholder reason for a type that could not be located
+	PlaceholderUnresolved = "unresolved"
+)
+
+// Placeholder represents resolution table entry for a type substituted with interface{}
+type Placeholder struct {
+	Owner       string //type owning the field
+	Field       string //field name
+	Target      string //intended type name
+	PackagePath string //intended type package path if known
+	Reason      string
+}
+
+// Placeholders returns placeholders recorded while resolving types
+func (t *DirTypes) Placeholders() []*Placeholder {
+	return t.placeholders
+}
+
+// Unresolved returns placeholders of types that could not be located
+func (t *DirTypes) Unresolved() []*Placeholder {
+	var result []*Placeholder
+	for _, candidate := range t.placeholders {
+		if candidate.Reason == PlaceholderUnresolved {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// Placeholder returns placeholder for supplied owner and field or nil
+func (t *DirTypes) Placeholder(owner, field string) *Placeholder {
+	for _, candidate := range t.placeholders {
+		if candidate.Owner == owner && candidate.Field == field {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func (t *DirTypes) addPlaceholder(placeholder *Placeholder) reflect.Type {
+	placeholder.Owner = t.owner
+	t.placeholders = append(t.placeholders, placeholder)
+	return InterfaceType
+}
+
+// placeholderTag returns placeholder tag fragment for a placeholder registered since offset, or empty string
+func (t *DirTypes) placeholderTag(offset int, fieldName string, fieldType reflect.Type) string {
+	if len(t.placeholders) <= offset || leafType(fieldType) != InterfaceType {
+		return ""
+	}
+	placeholder := t.placeholders[offset]
+	if placeholder.Field == "" {
+		placeholder.Field = fieldName
+	}
+	return TagTypeName + `:"` + placeholder.Target + `" ` + TagPlaceholder + `:"` + placeholder.Reason + `"`
+}
+
+// leafType returns ptr, slice, array and map value leaf type
+func leafType(rType reflect.Type) reflect.Type {
+	for {
+		switch rType.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			rType = rType.Elem()
+		default:
+			return rType
+		}
+	}
+}
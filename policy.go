@@ -0,0 +1,112 @@
+package xreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// OverridePolicy defines how registry handles a type registered under already taken name
+type OverridePolicy string
+
+const (
+	//OverrideReplace replaces previously registered type (default)
+	OverrideReplace = OverridePolicy("replace")
+	//OverrideError reports conflict
+	OverrideError = OverridePolicy("error")
+	//OverrideKeepFirst keeps previously registered type
+	OverrideKeepFirst = OverridePolicy("keepFirst")
+	//OverrideReplaceIfEqual replaces structurally equal type, reports conflict otherwise
+	OverrideReplaceIfEqual = OverridePolicy("replaceIfEqual")
+)
+
+// ErrTypeConflict reports type registered under already taken name
+var ErrTypeConflict = errors.New("type conflict")
+
+// ConflictError represents type registration conflict
+type ConflictError struct {
+	Package   string
+	Name      string
+	Existing  reflect.Type
+	Candidate reflect.Type
+	Final     bool
+}
+
+// Error returns error message
+func (e *ConflictError) Error() string {
+	name := e.Name
+	if e.Package != "" {
+		name = e.Package + "." + name
+	}
+	reason := ""
+	if e.Final {
+		reason = " (final package)"
+	}
+	return fmt.Sprintf("%v: %v%v, existing: %v, candidate: %v", ErrTypeConflict, name, reason, e.Existing, e.Candidate)
+}
+
+// Unwrap returns ErrTypeConflict
+func (e *ConflictError) Unwrap() error {
+	return ErrTypeConflict
+}
+
+// resolve returns type to be registered or conflict error
+func (p OverridePolicy) resolve(pkg *Package, name string, existing, candidate reflect.Type) (reflect.Type, error) {
+	if existing == nil || existing == candidate {
+		return candidate, nil
+	}
+	conflict := &ConflictError{Package: pkg.Name, Name: name, Existing: existing, Candidate: candidate}
+	if pkg.Final {
+		conflict.Final = true
+		return nil, conflict
+	}
+	switch p {
+	case OverrideError:
+		return nil, conflict
+	case OverrideKeepFirst:
+		return existing, nil
+	case OverrideReplaceIfEqual:
+		if !structurallyEqual(existing, candidate) {
+			return nil, conflict
+		}
+	}
+	return candidate, nil
+}
+
+// structurallyEqual returns true if types have the same shape, field names and tags regardless of naming
+func structurallyEqual(x, y reflect.Type) bool {
+	if x == y {
+		return true
+	}
+	if x.Kind() != y.Kind() {
+		return false
+	}
+	if x.Name() != "" && y.Name() != "" && x.Kind() != reflect.Struct {
+		return x.Name() == y.Name() && x.PkgPath() == y.PkgPath()
+	}
+	switch x.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return structurallyEqual(x.Elem(), y.Elem())
+	case reflect.Array:
+		return x.Len() == y.Len() && structurallyEqual(x.Elem(), y.Elem())
+	case reflect.Map:
+		return structurallyEqual(x.Key(), y.Key()) && structurallyEqual(x.Elem(), y.Elem())
+	case reflect.Struct:
+		if x.NumField() != y.NumField() {
+			return false
+		}
+		for i := 0; i < x.NumField(); i++ {
+			xField, yField := x.Field(i), y.Field(i)
+			if xField.Name != yField.Name || xField.Tag != yField.Tag || xField.Anonymous != yField.Anonymous {
+				return false
+			}
+			if !structurallyEqual(xField.Type, yField.Type) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		return x.NumMethod() == y.NumMethod()
+	}
+	return x.Name() == y.Name()
+}
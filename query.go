@@ -0,0 +1,228 @@
+package xreflect
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	//QueryResult represents type matched by query
+	QueryResult struct {
+		Package    string
+		ImportPath string
+		Name       string
+		Type       reflect.Type
+		Definition string
+		Methods    []reflect.Method
+		Fields     []reflect.StructField //fields matched by field predicates
+	}
+
+	//Predicate represents query predicate, field predicates record matched fields
+	Predicate func(result *QueryResult) bool
+)
+
+// TypeName returns package qualified type name
+func (r *QueryResult) TypeName() string {
+	if r.Package == "" {
+		return r.Name
+	}
+	return r.Package + "." + r.Name
+}
+
+// Query returns registry types matching all predicates, sorted by package and type name
+func (t *Types) Query(predicates ...Predicate) []*QueryResult {
+	var result []*QueryResult
+	for _, pkg := range t.packageMap() {
+		for typeName, rType := range pkg.typeMap() {
+			pkg.mux.RLock()
+			methods := pkg.methods[typeName]
+			pkg.mux.RUnlock()
+			candidate := &QueryResult{Package: pkg.Name, ImportPath: pkg.ImportPath, Name: typeName, Type: rType, Methods: methods}
+			if !matchAll(candidate, predicates) {
+				continue
+			}
+			candidate.Definition = typeDefinition(&Type{Name: typeName, Package: pkg.Name, ImportPath: pkg.ImportPath, Type: rType, Registry: t})
+			result = append(result, candidate)
+		}
+	}
+	sortQueryResults(result)
+	return result
+}
+
+// Query returns parsed types matching all predicates, sorted by package and type name
+func (t *DirTypes) Query(predicates ...Predicate) []*QueryResult {
+	var result []*QueryResult
+	for _, typeName := range t.TypesNames() {
+		rType, err := t.Type(typeName)
+		if err != nil {
+			continue
+		}
+		candidate := &QueryResult{Package: t.specPackage(typeName), ImportPath: t.ModulePath, Name: typeName, Type: rType}
+		for _, method := range t.Methods(typeName) {
+			candidate.Methods = append(candidate.Methods, AsMethod(method))
+		}
+		if !matchAll(candidate, predicates) {
+			continue
+		}
+		candidate.Definition = typeDefinition(&Type{Name: typeName, Package: candidate.Package, Type: rType})
+		result = append(result, candidate)
+	}
+	sortQueryResults(result)
+	return result
+}
+
+// HasFieldTag returns predicate matching struct with a field having supplied tag key
+func HasFieldTag(key string) Predicate {
+	return hasField(func(field *reflect.StructField) bool {
+		_, ok := field.Tag.Lookup(key)
+		return ok
+	})
+}
+
+// HasFieldTagValue returns predicate matching struct with a field having supplied tag value or comma separated tag value element
+func HasFieldTagValue(key, value string) Predicate {
+	return hasField(func(field *reflect.StructField) bool {
+		tagValue, ok := field.Tag.Lookup(key)
+		if !ok {
+			return false
+		}
+		if tagValue == value {
+			return true
+		}
+		for _, element := range strings.Split(tagValue, ",") {
+			if strings.TrimSpace(element) == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Embeds returns predicate matching struct embedding supplied type name, name can be package qualified
+func Embeds(name string) Predicate {
+	return hasField(func(field *reflect.StructField) bool {
+		if !field.Anonymous {
+			return false
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		for _, candidate := range []string{field.Tag.Get(TagTypeName), fieldType.Name(), field.Name} {
+			if candidate == "" {
+				continue
+			}
+			if candidate == name || strings.HasSuffix(name, "."+candidate) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HasMethod returns predicate matching type with supplied method
+func HasMethod(name string) Predicate {
+	return func(result *QueryResult) bool {
+		for _, method := range result.Methods {
+			if method.Name == name {
+				return true
+			}
+		}
+		if result.Type != nil {
+			if _, ok := result.Type.MethodByName(name); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasKind returns predicate matching type kind
+func HasKind(kind reflect.Kind) Predicate {
+	return func(result *QueryResult) bool {
+		return result.Type != nil && result.Type.Kind() == kind
+	}
+}
+
+// NumFields returns predicate matching struct with supplied number of fields
+func NumFields(n int) Predicate {
+	return func(result *QueryResult) bool {
+		return result.Type != nil && result.Type.Kind() == reflect.Struct && result.Type.NumField() == n
+	}
+}
+
+// Or returns predicate matching any of supplied predicates
+func Or(predicates ...Predicate) Predicate {
+	return func(result *QueryResult) bool {
+		matched := false
+		for _, predicate := range predicates {
+			if predicate(result) {
+				matched = true
+			}
+		}
+		return matched
+	}
+}
+
+// Not returns predicate negation
+func Not(predicate Predicate) Predicate {
+	return func(result *QueryResult) bool {
+		fields := result.Fields
+		matched := predicate(result)
+		result.Fields = fields
+		return !matched
+	}
+}
+
+func hasField(match func(field *reflect.StructField) bool) Predicate {
+	return func(result *QueryResult) bool {
+		if result.Type == nil || result.Type.Kind() != reflect.Struct {
+			return false
+		}
+		matched := false
+		for i := 0; i < result.Type.NumField(); i++ {
+			field := result.Type.Field(i)
+			if match(&field) {
+				matched = true
+				result.Fields = appendField(result.Fields, field)
+			}
+		}
+		return matched
+	}
+}
+
+func appendField(fields []reflect.StructField, field reflect.StructField) []reflect.StructField {
+	for _, candidate := range fields {
+		if candidate.Name == field.Name {
+			return fields
+		}
+	}
+	return append(fields, field)
+}
+
+func matchAll(result *QueryResult, predicates []Predicate) bool {
+	for _, predicate := range predicates {
+		if !predicate(result) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortQueryResults(results []*QueryResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ImportPath != results[j].ImportPath {
+			return results[i].ImportPath < results[j].ImportPath
+		}
+		return results[i].TypeName() < results[j].TypeName()
+	})
+}
+
+// typeDefinition returns struct body or type definition
+func typeDefinition(aType *Type) string {
+	if aType.Type == nil || aType.Type.Kind() == reflect.Struct {
+		return aType.Body()
+	}
+	return aType.Type.String()
+}
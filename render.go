@@ -0,0 +1,179 @@
+package xreflect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// typeRenderer renders Go type expressions, it is shared by GenerateStruct, Stringify and Type.Body
+type typeRenderer struct {
+	pkg string
+	//importPath is rendered package import path, it takes precedence over package name when both import paths are known
+	importPath string
+	registry   *Types
+	imports    *importSet
+	//qualify returns qualifier for named type package, it defaults to import set qualifier
+	qualify func(importPath, pkgName string) string
+	//anonymous renders anonymous struct, it returns false to fallback to type name or inline struct body
+	anonymous func(builder *strings.Builder, rType reflect.Type, typeName string) bool
+}
+
+// render writes type expression, type name replaces leaf following pointer, slice and array prefixes
+func (r *typeRenderer) render(builder *strings.Builder, rType reflect.Type, typeName string) {
+	if typeName == "" {
+		r.leaf(builder, r.prefix(builder, rType))
+		return
+	}
+	rType = r.sequencePrefix(builder, rType)
+	if rType.Name() == "" && rType.Kind() == reflect.Struct && r.anonymous != nil && r.anonymous(builder, rType, typeName) {
+		return
+	}
+	builder.WriteString(typeName)
+}
+
+// prefix writes pointer, slice, array and map prefixes of unnamed type and returns leaf type
+func (r *typeRenderer) prefix(builder *strings.Builder, rType reflect.Type) reflect.Type {
+	for {
+		rType = r.sequencePrefix(builder, rType)
+		if rType.Name() != "" || rType.Kind() != reflect.Map {
+			return rType
+		}
+		builder.WriteString("map[")
+		r.key(builder, rType.Key())
+		builder.WriteByte(']')
+		rType = rType.Elem()
+	}
+}
+
+// sequencePrefix writes pointer, slice and array prefixes of unnamed type and returns element type
+func (r *typeRenderer) sequencePrefix(builder *strings.Builder, rType reflect.Type) reflect.Type {
+	for rType.Name() == "" {
+		switch rType.Kind() {
+		case reflect.Ptr:
+			builder.WriteByte('*')
+		case reflect.Slice:
+			builder.WriteString("[]")
+		case reflect.Array:
+			builder.WriteString("[" + strconv.Itoa(rType.Len()) + "]")
+		default:
+			return rType
+		}
+		rType = rType.Elem()
+	}
+	return rType
+}
+
+// key writes map key type, anonymous struct keys are always inlined
+func (r *typeRenderer) key(builder *strings.Builder, rType reflect.Type) {
+	anonymous := r.anonymous
+	r.anonymous = nil
+	r.render(builder, rType, "")
+	r.anonymous = anonymous
+}
+
+func (r *typeRenderer) leaf(builder *strings.Builder, rType reflect.Type) {
+	if rType.Name() != "" {
+		builder.WriteString(r.named(rType))
+		return
+	}
+	switch rType.Kind() {
+	case reflect.Interface:
+		if rType.NumMethod() == 0 {
+			builder.WriteString("interface{}")
+			return
+		}
+		builder.WriteString(rType.String())
+	case reflect.Struct:
+		if r.anonymous != nil && r.anonymous(builder, rType, "") {
+			return
+		}
+		r.structBody(builder, rType)
+	default:
+		builder.WriteString(rType.String())
+	}
+}
+
+// underlying writes type definition, named type is rendered with its underlying type
+func (r *typeRenderer) underlying(builder *strings.Builder, rType reflect.Type) {
+	if rType.Name() == "" {
+		r.render(builder, rType, "")
+		return
+	}
+	switch rType.Kind() {
+	case reflect.Ptr:
+		builder.WriteByte('*')
+	case reflect.Slice:
+		builder.WriteString("[]")
+	case reflect.Array:
+		builder.WriteString("[" + strconv.Itoa(rType.Len()) + "]")
+	case reflect.Map:
+		builder.WriteString("map[")
+		r.key(builder, rType.Key())
+		builder.WriteByte(']')
+	case reflect.Struct:
+		r.structBody(builder, rType)
+		return
+	case reflect.Interface, reflect.Func, reflect.Chan:
+		builder.WriteString(rType.String())
+		return
+	default:
+		builder.WriteString(rType.Kind().String())
+		return
+	}
+	r.render(builder, rType.Elem(), "")
+}
+
+// structBody writes inline struct body, type name tag is preserved for interface and placeholder fields only
+func (r *typeRenderer) structBody(builder *strings.Builder, rType reflect.Type) {
+	builder.WriteString("struct{")
+	for i := 0; i < rType.NumField(); i++ {
+		aField := rType.Field(i)
+		fieldTag := strings.TrimSpace(string(aField.Tag))
+		typeName := ""
+		if !hasInterface(aField.Type) {
+			typeName = aField.Tag.Get(TagTypeName)
+			fieldTag, _ = RemoveTag(fieldTag, TagTypeName)
+		}
+		if !aField.Anonymous {
+			builder.WriteString(aField.Name)
+		}
+		builder.WriteString(" ")
+		r.render(builder, aField.Type, typeName)
+		if aField.Tag != "" {
+			builder.WriteString(" `" + fieldTag + "`")
+		}
+		builder.WriteString("; ")
+	}
+	builder.WriteString("}")
+}
+
+// isLocalPackage returns true if package is the rendered package, package names are compared only if either import path is unknown
+func isLocalPackage(pkg, importPath, localPkg, localImportPath string) bool {
+	if importPath != "" && localImportPath != "" {
+		return importPath == localImportPath
+	}
+	return pkg == localPkg
+}
+
+// named returns package qualified type name, local package types are not qualified
+func (r *typeRenderer) named(rType reflect.Type) string {
+	pkg := packageName(rType)
+	importPath := rType.PkgPath()
+	name := rType.Name()
+	if r.registry != nil && pkg != "" { //prefer name the type was registered with
+		if info := r.registry.Info(rType); info != nil && info.Name != "" {
+			pkg, name = info.Package, rawName(info.Name)
+			if info.ImportPath != "" {
+				importPath = info.ImportPath
+			}
+		}
+	}
+	if pkg == "" || isLocalPackage(pkg, importPath, r.pkg, r.importPath) {
+		return name
+	}
+	if r.qualify != nil {
+		return r.qualify(importPath, pkg) + "." + name
+	}
+	return r.imports.qualifier(importPath, pkg) + "." + name
+}
@@ -0,0 +1,73 @@
+package xreflect
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Scope creates child registry, scope types shadow parent types, parent types are never copied or substituted into the scope
+func (t *Types) Scope(name string, opts ...Option) *Types {
+	opts = append([]Option{WithRegistry(t), WithCacheDir(t.cacheDir), WithOverridePolicy(t.policy)}, opts...)
+	ret := NewTypes(opts...)
+	ret.scope = name
+	ret.scoped = true
+	return ret
+}
+
+// ScopeName returns scope name
+func (t *Types) ScopeName() string {
+	return t.scope
+}
+
+// Parent returns parent registry
+func (t *Types) Parent() *Types {
+	return t.parent
+}
+
+// Seal makes registry read only, any subsequent registry change returns ErrSealed
+func (t *Types) Seal() {
+	atomic.StoreUint32(&t.sealed, 1)
+}
+
+// IsSealed returns true if registry is read only
+func (t *Types) IsSealed() bool {
+	return atomic.LoadUint32(&t.sealed) == 1
+}
+
+// Discard releases registry own types and listeners and seals it, only parent types remain visible
+func (t *Types) Discard() {
+	t.Seal()
+	t.mux.Lock()
+	t.packages.Store(emptyPackageIndex)
+	t.info = map[reflect.Type]*Type{}
+	t.mux.Unlock()
+	t.listeners.mux.Lock()
+	t.listeners.items = nil
+	t.listeners.mux.Unlock()
+	atomic.AddUint64(&t.generation, 1)
+}
+
+// Origin returns registry in the parent chain the supplied type is resolved from
+func (t *Types) Origin(name string, opts ...Option) (*Types, error) {
+	aType := NewType(name, opts...)
+	for registry := t; registry != nil; registry = registry.parent {
+		pkg, err := registry.resolvePackage(aType.packageKey())
+		if err != nil {
+			return nil, err
+		}
+		if pkg == nil {
+			continue
+		}
+		if rType, _ := pkg.Lookup(aType.Name); rType != nil {
+			return registry, nil
+		}
+	}
+	return nil, newTypeError(ErrTypeNotFound, aType.Name, aType.Package, aType.PackagePath)
+}
+
+func (t *Types) checkWritable() error {
+	if t.IsSealed() {
+		return ErrSealed
+	}
+	return nil
+}
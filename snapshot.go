@@ -0,0 +1,195 @@
+package xreflect
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// resolvedTypes represents lookup cache valid for a registry chain version
+type resolvedTypes struct {
+	version []registryVersion
+	types   sync.Map //name to reflect.Type
+}
+
+// registryVersion represents registry generation, registry identity keeps version unique once parent is replaced
+type registryVersion struct {
+	registry   *Types
+	generation uint64
+}
+
+// version returns registry chain generations, it changes whenever registry, any of its parents or parent chain changes
+func (t *Types) version() []registryVersion {
+	var ret []registryVersion
+	for registry := t; registry != nil; registry = registry.parent {
+		ret = append(ret, registryVersion{registry: registry, generation: atomic.LoadUint64(&registry.generation)})
+	}
+	return ret
+}
+
+// isCurrent returns true if registry chain has not changed since version was taken, it does not allocate
+func (t *Types) isCurrent(version []registryVersion) bool {
+	registry := t
+	for _, candidate := range version {
+		if registry != candidate.registry || atomic.LoadUint64(&registry.generation) != candidate.generation {
+			return false
+		}
+		registry = registry.parent
+	}
+	return registry == nil
+}
+
+// cachedType returns previously resolved type without locking and allocation
+func (t *Types) cachedType(name string) reflect.Type {
+	resolved, _ := t.resolved.Load().(*resolvedTypes)
+	if resolved == nil || !t.isCurrent(resolved.version) {
+		return nil
+	}
+	if ret, ok := resolved.types.Load(name); ok {
+		return ret.(reflect.Type)
+	}
+	return nil
+}
+
+// cacheType stores resolved type if registry has not changed since lookup started, cache is replaced only once version changes
+func (t *Types) cacheType(name string, rType reflect.Type, version []registryVersion) {
+	t.resolvedMux.Lock()
+	defer t.resolvedMux.Unlock()
+	if !t.isCurrent(version) {
+		return
+	}
+	resolved, _ := t.resolved.Load().(*resolvedTypes)
+	if resolved == nil || !t.isCurrent(resolved.version) {
+		resolved = &resolvedTypes{version: version}
+		t.resolved.Store(resolved)
+	}
+	resolved.types.Store(name, rType)
+}
+
+// packageIndex represents immutable registry packages snapshot
+type packageIndex struct {
+	packages  map[string]*Package //keyed by import path, or by name if import path is unknown
+	names     map[string][]string //short names and aliases to package keys
+	aliases   map[string]string
+	locations map[string]string
+}
+
+var emptyPackageIndex = &packageIndex{}
+
+func (t *Types) packageIndex() *packageIndex {
+	if ret, _ := t.packages.Load().(*packageIndex); ret != nil {
+		return ret
+	}
+	return emptyPackageIndex
+}
+
+// packageMap returns immutable packages snapshot keyed by package import path
+func (t *Types) packageMap() map[string]*Package {
+	return t.packageIndex().packages
+}
+
+// lookupPackage returns package for supplied import path, unambiguous short name or alias
+func (t *Types) lookupPackage(name string) *Package {
+	ret, _ := t.resolvePackage(name)
+	return ret
+}
+
+// resolvePackage returns package for supplied import path, short name or alias, or error if name is ambiguous
+func (t *Types) resolvePackage(name string) (*Package, error) {
+	index := t.packageIndex()
+	if ret, ok := index.packages[name]; ok {
+		return ret, nil
+	}
+	switch keys := index.names[name]; len(keys) {
+	case 0:
+		return nil, nil
+	case 1:
+		return index.packages[keys[0]], nil
+	default:
+		return nil, &AmbiguousPackageError{Name: name, Candidates: keys}
+	}
+}
+
+// locationPackage returns package parsed from supplied location
+func (t *Types) locationPackage(location string) *Package {
+	index := t.packageIndex()
+	if key, ok := index.locations[location]; ok {
+		return index.packages[key]
+	}
+	return nil
+}
+
+// storePackages publishes packages with copy of the current snapshot, caller has to hold write lock
+func (t *Types) storePackages(packages ...*Package) {
+	t.updatePackages(func(index *packageIndex) {
+		for _, pkg := range packages {
+			index.packages[pkg.key()] = pkg
+			if pkg.Path != "" {
+				index.locations[pkg.Path] = pkg.key()
+			}
+		}
+	})
+}
+
+// updatePackages publishes modified copy of the current snapshot and bumps registry generation, caller has to hold write lock
+func (t *Types) updatePackages(update func(index *packageIndex)) {
+	current := t.packageIndex()
+	next := &packageIndex{
+		packages:  make(map[string]*Package, len(current.packages)+1),
+		aliases:   make(map[string]string, len(current.aliases)),
+		locations: make(map[string]string, len(current.locations)),
+	}
+	for k, v := range current.packages {
+		next.packages[k] = v
+	}
+	for k, v := range current.aliases {
+		next.aliases[k] = v
+	}
+	for k, v := range current.locations {
+		next.locations[k] = v
+	}
+	update(next)
+	next.names = map[string][]string{}
+	for key, pkg := range next.packages {
+		if pkg.Name != key {
+			next.names[pkg.Name] = append(next.names[pkg.Name], key)
+		}
+	}
+	for alias, key := range next.aliases {
+		if _, ok := next.packages[key]; ok {
+			next.names[alias] = append(next.names[alias], key)
+		}
+	}
+	for _, keys := range next.names {
+		sort.Strings(keys)
+	}
+	t.packages.Store(next)
+	atomic.AddUint64(&t.generation, 1) //short names may resolve to different package
+}
+
+// typeMap returns immutable package types snapshot
+func (p *Package) typeMap() map[string]reflect.Type {
+	if ret, ok := p.snapshot.Load().(map[string]reflect.Type); ok {
+		return ret
+	}
+	p.mux.RLock()
+	ret := p.Types
+	p.mux.RUnlock()
+	return ret
+}
+
+// setTypes replaces package types, caller has to hold write lock and must not modify types afterwards
+func (p *Package) setTypes(types map[string]reflect.Type) {
+	p.Types = types
+	p.snapshot.Store(types)
+}
+
+// copyTypes returns a copy of package types, caller has to hold a lock
+func (p *Package) copyTypes() map[string]reflect.Type {
+	ret := make(map[string]reflect.Type, len(p.Types)+1)
+	for k, v := range p.Types {
+		ret[k] = v
+	}
+	return ret
+}
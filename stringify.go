@@ -108,38 +108,23 @@ func stringify(expr ast.Node, builder *strings.Builder) error {
 		builder.WriteString("*")
 		return stringify(actual.X, builder)
 	default:
-		return fmt.Errorf("unsupported node: %T", actual)
+		return newTypeError(ErrUnsupportedNode, fmt.Sprintf("%T", actual), "", "")
 	}
 	return nil
 }
 
+// Stringify returns Go type expression, type name tag replaces leaf following pointer, slice and array prefixes
 func Stringify(rType reflect.Type, tag reflect.StructTag) string {
+	typeName := tag.Get(TagTypeName)
+	if typeName == "" {
+		return rType.String()
+	}
 	builder := &strings.Builder{}
-	stringifyWithBuilder(rType, tag, builder)
+	renderer := &typeRenderer{}
+	renderer.render(builder, rType, typeName)
 	return builder.String()
 }
 
-func stringifyWithBuilder(rType reflect.Type, tag reflect.StructTag, builder *strings.Builder) {
-	aliasedTypeName := tag.Get(TagTypeName)
-	if aliasedTypeName == "" {
-		builder.WriteString(rType.String())
-		return
-	}
-	for {
-		switch rType.Kind() {
-		case reflect.Ptr:
-			builder.WriteByte('*')
-			rType = rType.Elem()
-		case reflect.Slice, reflect.Array:
-			builder.WriteString("[]")
-			rType = rType.Elem()
-		default:
-			builder.WriteString(aliasedTypeName)
-			return
-		}
-	}
-}
-
 func baseType(rType reflect.Type) reflect.Type {
 	if rType == nil {
 		return nil
@@ -168,11 +153,12 @@ func (t *Type) String() string {
 		return "type " + t.Name + " " + body
 	}
 	builder := strings.Builder{}
-	tag := reflect.StructTag(TagTypeName + `:"` + t.Name + `"`)
+	renderer := t.renderer()
 	builder.WriteString("type ")
-	t.stringifyWithBuilder(t.Type, tag, &builder)
+	renderer.render(&builder, t.Type, t.Name)
 	builder.WriteString(" ")
-	return t.body(&builder)
+	t.body(&builder, renderer)
+	return builder.String()
 }
 
 func (t *Type) Body() string {
@@ -183,54 +169,27 @@ func (t *Type) Body() string {
 		return strings.TrimSpace(t.Definition)
 	}
 	builder := strings.Builder{}
-	t.body(&builder)
+	t.body(&builder, t.renderer())
 	return builder.String()
 }
 
-func (t *Type) body(builder *strings.Builder) string {
-	t.stringify(t.Type, "", builder, true)
-	return builder.String()
+func (t *Type) renderer() *typeRenderer {
+	return &typeRenderer{pkg: t.Package, importPath: t.ImportPath, registry: t.Registry, imports: newImportSet(t.Imports)}
 }
 
-func (t *Type) stringify(rType reflect.Type, tag reflect.StructTag, builder *strings.Builder, forceBody bool) {
-	bType := baseType(rType)
-	switch bType.Kind() {
-	case reflect.Interface:
-		builder.WriteString("interface{}")
-	case reflect.Struct:
-		if bType.Name() != "" && !forceBody {
-			return
-		}
-		builder.WriteString("struct{")
-		for i := 0; i < bType.NumField(); i++ {
-			aField := bType.Field(i)
-			fieldTag := string(aField.Tag)
-			isIface := hasInterface(aField.Type)
-			if !isIface { //preserve type name for interface type
-				fieldTag, _ = RemoveTag(string(aField.Tag), TagTypeName)
-			}
-			if !aField.Anonymous {
-				builder.WriteString(aField.Name)
-			}
-			builder.WriteString(" ")
-			typedStrigified := t.stringifyWithBuilder(aField.Type, aField.Tag, builder)
-			if !typedStrigified {
-				t.stringify(aField.Type, aField.Tag, builder, false)
-			}
-			if aField.Tag != "" {
-				builder.WriteString(" `" + fieldTag + "`")
-			}
-			builder.WriteString("; ")
-		}
-		builder.WriteString("}")
+func (t *Type) body(builder *strings.Builder, renderer *typeRenderer) {
+	if bType := baseType(t.Type); bType.Kind() == reflect.Struct {
+		renderer.structBody(builder, bType)
+		return
 	}
+	renderer.underlying(builder, t.Type)
 }
 
 func hasInterface(aType reflect.Type) bool {
 	switch aType.Kind() {
 	case reflect.Ptr:
 		return hasInterface(aType.Elem())
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array, reflect.Map:
 		return hasInterface(aType.Elem())
 	case reflect.Interface:
 		return true
@@ -273,55 +232,6 @@ func RemoveTag(tag string, tagName string) (string, string) {
 	return tag, fragment
 }
 
-func (t *Type) stringifyWithBuilder(rType reflect.Type, tag reflect.StructTag, builder *strings.Builder) bool {
-	typeName := tag.Get(TagTypeName)
-	if rType.Name() != "" {
-		builder.WriteString(t.namedType(rType))
-		return true
-	}
-	for {
-		switch rType.Kind() {
-		case reflect.Interface:
-			builder.WriteString("interface{}")
-			return true
-		case reflect.Ptr:
-			builder.WriteByte('*')
-			rType = rType.Elem()
-			if rType.Name() != "" {
-				builder.WriteString(t.namedType(rType))
-				return true
-			}
-		case reflect.Slice, reflect.Array:
-			builder.WriteString("[]")
-			rType = rType.Elem()
-		case reflect.Map:
-			builder.WriteString("map[")
-			if rType.Key().Name() != "" {
-				builder.WriteString(rType.Key().Name())
-			} else {
-				builder.WriteString(rType.Key().String())
-			}
-			builder.WriteString("]")
-			rType = rType.Elem()
-
-		default:
-			if typeName == "" {
-				typeName = t.namedType(rType)
-			}
-			builder.WriteString(typeName)
-			return typeName != ""
-		}
-	}
-}
-
-func (t *Type) namedType(rType reflect.Type) string {
-	pkg := relativePackage(rType)
-	if pkg != "" && pkg != t.Package {
-		return pkg + "." + rType.Name()
-	}
-	return rType.Name()
-}
-
 func trim(tag string, c byte) string {
 	if tag == "" {
 		return ""
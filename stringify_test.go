@@ -3,8 +3,10 @@ package xreflect
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	htmltemplate "html/template"
 	"reflect"
 	"testing"
+	texttemplate "text/template"
 	"time"
 )
 
@@ -30,6 +32,35 @@ func TestStringify(t *testing.T) {
 			expected: "[]*Foo",
 			tag:      fmt.Sprintf(`%v:"Foo"`, TagTypeName),
 		},
+		{
+			description: "slice of basic type",
+			rType:       reflect.TypeOf([]int{}),
+			expected:    "[]IDs",
+			tag:         fmt.Sprintf(`%v:"IDs"`, TagTypeName),
+		},
+		{
+			description: "slice of interface pointers",
+			rType:       reflect.TypeOf([]*interface{}{}),
+			expected:    "[]*Node",
+			tag:         fmt.Sprintf(`%v:"Node"`, TagTypeName),
+		},
+		{
+			description: "map",
+			rType:       reflect.TypeOf(map[string]int{}),
+			expected:    "IDs",
+			tag:         fmt.Sprintf(`%v:"IDs"`, TagTypeName),
+		},
+		{
+			description: "array",
+			rType:       reflect.TypeOf([2]*interface{}{}),
+			expected:    "[2]*Node",
+			tag:         fmt.Sprintf(`%v:"Node"`, TagTypeName),
+		},
+		{
+			description: "without type name",
+			rType:       reflect.TypeOf(map[string]int{}),
+			expected:    "map[string]int",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -80,6 +111,13 @@ func TestType_Body(t *testing.T) {
 			Type:   NewType("Foo", WithReflectType(reflect.TypeOf(foo)), WithPackage("xreflect")),
 			expect: "struct{Time *time.Time `format:\"tz=utc\"`; Id int `json:\"Id\"`; Test Test ``; Inline struct{secret string; Name string; }; Bar Bar; }",
 		},
+		{
+			description: "map placeholder",
+			Type: NewType("Node", WithPackage("xreflect"), WithReflectType(reflect.StructOf([]reflect.StructField{
+				{Name: "Index", Type: reflect.TypeOf(map[string]*interface{}{}), Tag: ` typeName:"Node" placeholder:"cycle"`},
+			}))),
+			expect: "struct{Index map[string]*interface{} `typeName:\"Node\" placeholder:\"cycle\"`; }",
+		},
 	}
 	for _, testCase := range testCases {
 		actual := testCase.Type.Body()
@@ -137,3 +175,41 @@ func TestType_Body_WithNamedDefinitionAndNilReflectType(t *testing.T) {
 	assert.Equal(t, "map[string]interface{}", aType.Body())
 	assert.Equal(t, "type Elicitation map[string]interface{}", aType.String())
 }
+
+func TestType_Body_CollidingPackages(t *testing.T) {
+	rType := reflect.StructOf([]reflect.StructField{
+		{Name: "Text", Type: reflect.TypeOf(&texttemplate.Template{})},
+		{Name: "HTML", Type: reflect.TypeOf([]*htmltemplate.Template{})},
+	})
+	testCases := []struct {
+		description string
+		imports     GoImports
+		expect      string
+	}{
+		{description: "alias assigned in order of appearance", expect: "struct{Text *template.Template; HTML []*htmltemplate.Template; }"},
+		{description: "alias from imports", imports: GoImports{{Name: "ttemplate", Module: "text/template"}}, expect: "struct{Text *ttemplate.Template; HTML []*template.Template; }"},
+	}
+	for _, testCase := range testCases {
+		aType := NewType("Foo", WithReflectType(rType), WithGoImports(testCase.imports))
+		assert.Equal(t, testCase.expect, aType.Body(), testCase.description)
+	}
+}
+
+func TestType_Body_ComplexTypes(t *testing.T) {
+	testCases := []struct {
+		description string
+		rType       reflect.Type
+		expect      string
+	}{
+		{description: "qualified map key", rType: reflect.TypeOf(map[time.Duration][]*time.Time{}), expect: "map[time.Duration][]*time.Time"},
+		{description: "array map key", rType: reflect.TypeOf(map[[2]int]string{}), expect: "map[[2]int]string"},
+		{description: "struct map key", rType: reflect.TypeOf(map[struct{ ID int }]bool{}), expect: "map[struct{ID int; }]bool"},
+		{description: "nested containers", rType: reflect.TypeOf(map[string]map[string][]*struct{ Name string }{}), expect: "map[string]map[string][]*struct{Name string; }"},
+		{description: "named underlying type", rType: reflect.TypeOf(time.Duration(0)), expect: "int64"},
+		{description: "struct with array field", rType: reflect.TypeOf(struct{ Pair [2]time.Time }{}), expect: "struct{Pair [2]time.Time; }"},
+	}
+	for _, testCase := range testCases {
+		aType := NewType("Foo", WithReflectType(testCase.rType))
+		assert.Equal(t, testCase.expect, aType.Body(), testCase.description)
+	}
+}
@@ -1,10 +1,13 @@
 package xreflect
 
 import (
-	"go/format"
+	"go/token"
+	"io"
+	"path"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 type (
@@ -13,23 +16,75 @@ type (
 	PackageName      string
 )
 
+// GenerateStruct returns formatted struct source, unformatted source is returned if it does not parse, use GenerateStructTo to get errors
 func GenerateStruct(name string, structType reflect.Type, opts ...Option) string {
 	genOptions := &options{}
 	genOptions.Apply(opts...)
 	genOptions.initGen()
-	typeBuilder := newTypeBuilder(name)
+	generated := generateStruct(name, structType, genOptions)
+	file, err := parseGenerated(name, generated, token.NewFileSet())
+	if err != nil {
+		return generated
+	}
+	builder := &strings.Builder{}
+	if err = file.Format(builder); err != nil {
+		return generated
+	}
+	return builder.String()
+}
+
+// GenerateStructTo writes formatted struct source, invalid identifiers, unsupported types, invalid defaults and source that does not parse are reported as GenerateError
+func GenerateStructTo(w io.Writer, name string, structType reflect.Type, opts ...Option) error {
+	file, err := GenerateStructAST(name, structType, opts...)
+	if err != nil {
+		return err
+	}
+	return file.Format(w)
+}
+
+// validateGenerate returns error if generated type, package or field name is not valid identifier or type can not be expressed
+func validateGenerate(name string, structType reflect.Type, opts *options) error {
+	if err := validateIdentifier(opts.Package, "package"); err != nil {
+		return err
+	}
+	if _, err := fileHeader(false, opts.buildConstraint); err != nil {
+		return err
+	}
+	types := append([]*Type{{Name: name, Type: structType}}, opts.withTypes...)
+	for _, aType := range types {
+		if err := validateIdentifier(aType.Name, "type"); err != nil {
+			return err
+		}
+		if aType.Type == nil {
+			return &GenerateError{Err: ErrUnsupportedType, Name: aType.Name + " (nil)"}
+		}
+		rType := aType.Type
+		if rType.Kind() == reflect.Ptr {
+			rType = rType.Elem()
+		}
+		if err := validateGoType(rType, aType.Name, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateStruct returns unformatted struct source
+func generateStruct(name string, structType reflect.Type, genOptions *options) string {
+	typeBuilder := newTypeBuilder(name, genOptions.Doc)
 	if structType.Kind() == reflect.Ptr {
 		structType = structType.Elem()
 	}
 	importsBuilder := &strings.Builder{}
-
+	imports := newImportSet(nil)
 	for _, imported := range genOptions.imports {
+		imports.qualifier(imported, path.Base(imported))
 		importsBuilder.WriteByte('"')
 		importsBuilder.WriteString(imported)
 		importsBuilder.WriteString("\"\n")
 	}
 
-	dependencyTypes := buildGoType(typeBuilder, importsBuilder, structType, map[string]bool{}, true, genOptions)
+	dependencyTypes := buildGoType(typeBuilder, importsBuilder, name, structType, imports, genOptions)
 	additionalTypeBuilder := strings.Builder{}
 	for _, aType := range genOptions.withTypes {
 		if genOptions.buildTypes[aType.TypeName()] {
@@ -38,8 +93,8 @@ func GenerateStruct(name string, structType reflect.Type, opts ...Option) string
 		genOptions.buildTypes[aType.TypeName()] = true
 
 		additionalTypeBuilder.WriteString("\n\n")
-		aTypeBuilder := newTypeBuilder(aType.Name)
-		dep := buildGoType(aTypeBuilder, importsBuilder, aType.Type, map[string]bool{}, true, genOptions)
+		aTypeBuilder := newTypeBuilder(aType.Name, aType.Doc)
+		dep := buildGoType(aTypeBuilder, importsBuilder, aType.Name, aType.Type, imports, genOptions)
 		additionalTypeBuilder.WriteString(aTypeBuilder.String())
 		for _, builder := range dep {
 			additionalTypeBuilder.WriteString("\n\n")
@@ -47,27 +102,39 @@ func GenerateStruct(name string, structType reflect.Type, opts ...Option) string
 		}
 	}
 
-	generated := build(importsBuilder, typeBuilder, dependencyTypes, genOptions.snippetBefore, genOptions.Package)
+	generated, _ := fileHeader(genOptions.header, genOptions.buildConstraint) //invalid constraint is reported by validateGenerate and omitted by GenerateStruct
+	generated += build(importsBuilder, typeBuilder, dependencyTypes, genOptions.snippetBefore, genOptions.Package)
 	generated += additionalTypeBuilder.String()
 	if genOptions.snippetAfter != "" {
 		generated += genOptions.snippetAfter
 	}
-	source, err := format.Source([]byte(generated))
-	if err == nil {
-		return string(source)
-	}
-
 	return generated
 }
 
-func newTypeBuilder(name string) *strings.Builder {
+func newTypeBuilder(name string, doc string) *strings.Builder {
 	structBuilder := &strings.Builder{}
+	appendDoc(structBuilder, doc)
 	structBuilder.WriteString("type ")
 	structBuilder.WriteString(name)
 	structBuilder.WriteString(" ")
 	return structBuilder
 }
 
+// appendDoc writes doc comment, one comment line per doc line
+func appendDoc(builder *strings.Builder, doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		if line = strings.TrimRightFunc(line, unicode.IsSpace); line == "" {
+			builder.WriteString("//\n")
+			continue
+		}
+		builder.WriteString("// " + line + "\n")
+	}
+}
+
 func build(importsBuilder *strings.Builder, structBuilder *strings.Builder, types []*strings.Builder, beforeType string, packageName string) string {
 	result := strings.Builder{}
 	result.WriteString("package ")
@@ -95,149 +162,263 @@ func build(importsBuilder *strings.Builder, structBuilder *strings.Builder, type
 	return result.String()
 }
 
-func buildGoType(mainBuilder *strings.Builder, importsBuilder *strings.Builder, structType reflect.Type, imports map[string]bool, isMain bool, opts *options) []*strings.Builder {
-	structType = appendElem(mainBuilder, structType)
-	appendImportIfNeeded(importsBuilder, structType.PkgPath(), imports, isMain, opts)
-
+func buildGoType(mainBuilder *strings.Builder, importsBuilder *strings.Builder, name string, structType reflect.Type, imports *importSet, opts *options) []*strings.Builder {
 	var structBuilders []*strings.Builder
-	switch structType.Kind() {
-	case reflect.Struct:
-		numField := structType.NumField()
-		mainBuilder.WriteString(" struct ")
-
-		mainBuilder.WriteString("{")
-		for i := 0; i < numField; i++ {
-			mainBuilder.WriteString("\n    ")
-			aField := structType.Field(i)
-			fieldTag, typeName := RemoveTag(string(aField.Tag), TagTypeName)
-			if aField.Type.Name() == "" && typeName == "" {
-				aType := resolveType(aField.Type, opts.Registry)
-				updateType(aType, &aField, opts, importsBuilder, imports, isMain)
-			}
-			doc := ""
-			if opts.onStructField != nil {
-				opts.onStructField(&aField, &fieldTag, &typeName, &doc)
-			}
-			if doc != "" {
-				mainBuilder.WriteString("// " + strings.TrimSpace(doc) + "\n")
-			}
-			if !aField.Anonymous {
-				mainBuilder.WriteString(aField.Name)
-				mainBuilder.WriteByte(' ')
+	renderer := &typeRenderer{pkg: opts.Package, importPath: opts.ImportPath, imports: imports}
+	renderer.qualify = func(importPath, pkgName string) string {
+		return appendImportIfNeeded(importsBuilder, importPath, pkgName, imports, false, opts)
+	}
+	leaf := renderer.prefix(mainBuilder, structType)
+	if leaf.Kind() != reflect.Struct || (leaf.Name() != "" && leaf != structType) {
+		renderer.leaf(mainBuilder, leaf)
+		return structBuilders
+	}
+	structType = leaf
+	numField := structType.NumField()
+	mainBuilder.WriteString(" struct ")
+
+	mainBuilder.WriteString("{")
+	var accessors []*accessorField
+	var inits []*initField
+	var methodFields []*methodField
+	var jsonTypes []*fieldTypes
+	for i := 0; i < numField; i++ {
+		mainBuilder.WriteString("\n    ")
+		aField := structType.Field(i)
+		fieldTag, typeName := RemoveTag(string(aField.Tag), TagTypeName)
+		placeholder := aField.Tag.Get(TagPlaceholder)
+		if placeholder != "" {
+			fieldTag, _ = RemoveTag(fieldTag, TagPlaceholder)
+			typeName = aField.Tag.Get(TagTypeName)
+		}
+		accessor, hasAccessorTag := aField.Tag.Lookup(TagAccessor)
+		if hasAccessorTag {
+			fieldTag, _ = RemoveTag(fieldTag, TagAccessor)
+		}
+		if aField.Type.Name() == "" && typeName == "" {
+			aType := resolveType(aField.Type, opts.Registry)
+			updateType(aType, &aField, opts, importsBuilder, imports)
+		}
+		doc, hasDocTag := aField.Tag.Lookup(TagDoc)
+		if hasDocTag {
+			fieldTag, _ = RemoveTag(fieldTag, TagDoc)
+		} else if opts.fieldDoc != nil {
+			doc = opts.fieldDoc(name, aField.Name)
+		}
+		if doc == "" && !hasDocTag {
+			doc = aField.Tag.Get(TagDescription)
+		}
+		if opts.onStructField != nil {
+			opts.onStructField(&aField, &fieldTag, &typeName, &doc)
+		}
+		appendDoc(mainBuilder, doc)
+		if !aField.Anonymous {
+			mainBuilder.WriteString(aField.Name)
+			mainBuilder.WriteByte(' ')
+		}
+		typeOffset := mainBuilder.Len()
+		if placeholder != "" && typeName != "" { //reproduce intended type i.e. recursive type reference
+			renderer.prefix(mainBuilder, aField.Type)
+			mainBuilder.WriteString(typeName)
+		} else {
+			field := aField
+			renderer.anonymous = func(builder *strings.Builder, rType reflect.Type, _ string) bool {
+				structBuilders = append(structBuilders, buildNestedType(builder, importsBuilder, &field, rType, imports, opts)...)
+				return true
 			}
-			var actualType reflect.Type
-			fullyRendered := false
-			if aField.Type.Name() != "" && aField.Type.String() != "" {
-				actualType = aField.Type
-			} else {
-				actualType = appendElem(mainBuilder, aField.Type)
-				fullyRendered = isFullyRenderedByAppendElem(aField.Type)
+			renderer.render(mainBuilder, aField.Type, "")
+			renderer.anonymous = nil
+		}
+		typeExpr := mainBuilder.String()[typeOffset:]
+		if opts.hasAccessors() && !aField.Anonymous {
+			if field := newAccessorField(aField.Name, typeExpr, accessor); field != nil {
+				accessors = append(accessors, field)
 			}
-			if fullyRendered {
-				// appendElem already wrote the full type (for example interface{} leafs in map[string]interface{}).
-			} else {
-				mainBuilder.WriteByte(' ')
+		}
+		if opts.deepCopy || opts.equal {
+			if field := newMethodField(&aField, newFieldTypes(&aField, importsBuilder, imports, opts)); field != nil {
+				methodFields = append(methodFields, field)
 			}
-			if !fullyRendered && actualType.Kind() == reflect.Map {
-				mainBuilder.WriteString("map[")
-				mainBuilder.WriteString(actualType.Key().Name())
-				mainBuilder.WriteByte(']')
-				actualType = actualType.Elem()
-			} else if !fullyRendered && actualType.Kind() == reflect.Struct {
-				if actualType.Name() == "" {
-					typeName := firstNotEmptyString(aField.Tag.Get(TagTypeName), aField.Name)
-					pkgType := opts.generateOption.getPackageType(typeName)
-					if pkgType != nil {
-						appendImportIfNeeded(importsBuilder, pkgType.Package, imports, false, opts)
-						pkgTypeName := pkgType.Package + "." + typeName
-						mainBuilder.WriteString(pkgTypeName)
-
-					} else {
-						mainBuilder.WriteString(typeName)
-						skipTypeGeneration := false
-						if opts.skipFieldType != nil {
-							skipTypeGeneration = opts.skipFieldType(&aField)
-						}
-
-						nestedStruct := &strings.Builder{}
-						structBuilders = append(structBuilders, nestedStruct)
-						if !strings.Contains(typeName, ".") && !skipTypeGeneration {
-							if !opts.generateOption.buildTypes[typeName] {
-								opts.generateOption.buildTypes[typeName] = true
-								nestedStruct.WriteString("type ")
-								nestedStruct.WriteString(typeName)
-								nestedStruct.WriteByte(' ')
-								structBuilders = append(structBuilders, buildGoType(nestedStruct, importsBuilder, actualType, imports, false, opts)...)
-							}
-						} else {
-							_, name := splitPackage(typeName)
-							if aType := opts.getPackageType(name); aType != nil && aType.Package != opts.Package {
-								appendImportIfNeeded(importsBuilder, aType.ModulePath, imports, false, opts)
-							}
-
-						}
-					}
-				} else {
-
-					fieldTypeName := actualType.String()
-					packageName, typeName := splitPackage(fieldTypeName)
-					if packageName == opts.Package {
-						fieldTypeName = typeName
-					} else {
-						appendImportIfNeeded(importsBuilder, actualType.PkgPath(), imports, false, opts)
-					}
-					mainBuilder.WriteString(fieldTypeName)
-				}
-			} else if !fullyRendered {
-				if fieldTypeName := aField.Type.String(); fieldTypeName != "" && aField.Type.Name() != "" {
-					fieldTypeName := aField.Type.String()
-					if idx := strings.Index(fieldTypeName, "."); idx != -1 {
-						appendImportIfNeeded(importsBuilder, aField.Type.PkgPath(), imports, false, opts)
-					}
-					mainBuilder.WriteString(fieldTypeName)
-
-				} else {
-					structBuilders = append(structBuilders, buildGoType(mainBuilder, importsBuilder, actualType, imports, false, opts)...)
-				}
+		}
+		if opts.jsonCodec {
+			jsonTypes = append(jsonTypes, newFieldTypes(&aField, importsBuilder, imports, opts))
+		}
+		if opts.constructors {
+			if field := newInitField(&aField, typeExpr, isNestedType(&aField, opts)); field != nil {
+				inits = append(inits, field)
 			}
+		}
 
-			tagValue := fieldTag
-			if tagValue != "" {
-				quoteChar := "`"
-				if strings.Contains(string(tagValue), "`") {
-					quoteChar = `"`
-					tagValue = strconv.Quote(string(tagValue))
-				}
-				mainBuilder.WriteByte(' ')
-				mainBuilder.WriteString(quoteChar)
-				mainBuilder.WriteString(tagValue)
-				mainBuilder.WriteString(quoteChar)
+		tagValue := fieldTag
+		if tagValue != "" {
+			quoteChar := "`"
+			if strings.Contains(string(tagValue), "`") {
+				quoteChar = `"`
+				tagValue = strconv.Quote(string(tagValue))
 			}
+			mainBuilder.WriteByte(' ')
+			mainBuilder.WriteString(quoteChar)
+			mainBuilder.WriteString(tagValue)
+			mainBuilder.WriteString(quoteChar)
 		}
-		mainBuilder.WriteString("\n}")
+	}
+	presenceField := presenceFieldName(structType)
+	if opts.presence && len(accessors) > 0 {
+		appendPresenceField(mainBuilder, presenceField, accessors)
+	}
+	mainBuilder.WriteString("\n}")
+	if opts.constructors {
+		appendConstructor(mainBuilder, name, inits)
+	}
+	if len(accessors) > 0 {
+		appendAccessors(mainBuilder, name, presenceField, accessors, &opts.generateOption)
+	}
+	if opts.deepCopy {
+		appendDeepCopyMethods(mainBuilder, name, methodFields)
+	}
+	if opts.equal {
+		appendEqualMethod(mainBuilder, name, methodFields)
+	}
+	if opts.jsonCodec {
+		fields := jsonFields(structType, func(index []int, aField *reflect.StructField) *fieldTypes {
+			if len(index) == 1 {
+				return jsonTypes[index[0]]
+			}
+			return newFieldTypes(aField, importsBuilder, imports, opts)
+		})
+		appendJSONMethods(mainBuilder, name, fields, newStructTypes(importsBuilder, imports, opts))
+	}
+	return structBuilders
+}
+
+// fieldTypes renders types nested in a struct field, anonymous structs are named after the field
+type fieldTypes struct {
+	field          *reflect.StructField
+	nested         *string
+	importsBuilder *strings.Builder
+	renderer       *typeRenderer
+	opts           *options
+}
 
-	default:
-		mainBuilder.WriteString(structType.String())
+func newFieldTypes(aField *reflect.StructField, importsBuilder *strings.Builder, imports *importSet, opts *options) *fieldTypes {
+	ret := newStructTypes(importsBuilder, imports, opts)
+	ret.field, ret.nested = aField, nil
+	ret.renderer.anonymous = func(builder *strings.Builder, rType reflect.Type, _ string) bool {
+		builder.WriteString(ret.nestedName())
+		return true
 	}
+	return ret
+}
 
-	return structBuilders
+// newStructTypes returns types renderer without field context, anonymous structs are rendered inline
+func newStructTypes(importsBuilder *strings.Builder, imports *importSet, opts *options) *fieldTypes {
+	inline := ""
+	ret := &fieldTypes{nested: &inline, importsBuilder: importsBuilder, opts: opts}
+	ret.renderer = &typeRenderer{pkg: opts.Package, importPath: opts.ImportPath, imports: imports}
+	ret.renderer.qualify = func(importPath, pkgName string) string {
+		return appendImportIfNeeded(importsBuilder, importPath, pkgName, imports, false, opts)
+	}
+	return ret
+}
+
+// nestedName returns field anonymous struct type name
+func (f *fieldTypes) nestedName() string {
+	if f.nested == nil {
+		name := nestedTypeName(f.field, f.importsBuilder, f.renderer.imports, f.opts)
+		f.nested = &name
+	}
+	return *f.nested
+}
+
+// expr returns type expression
+func (f *fieldTypes) expr(rType reflect.Type) string {
+	builder := strings.Builder{}
+	f.renderer.render(&builder, rType, "")
+	return builder.String()
+}
+
+// qualify returns imported package qualifier
+func (f *fieldTypes) qualify(importPath, pkgName string) string {
+	return f.renderer.qualify(importPath, pkgName)
+}
+
+// generated returns true if struct type declaration is generated with the field owner
+func (f *fieldTypes) generated(rType reflect.Type) bool {
+	if rType.Kind() != reflect.Struct {
+		return false
+	}
+	if rType.Name() == "" {
+		name := f.nestedName()
+		return !strings.Contains(name, ".") && f.opts.generateOption.buildTypes[name]
+	}
+	return rType.PkgPath() != "" && packageName(rType) == f.opts.Package && f.opts.generateOption.buildTypes[rType.Name()]
+}
+
+// isNestedType returns true if field is anonymous struct or struct pointer with generated type declaration
+func isNestedType(aField *reflect.StructField, opts *options) bool {
+	rType := aField.Type
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType.Kind() != reflect.Struct || rType.Name() != "" {
+		return false
+	}
+	return opts.generateOption.buildTypes[firstNotEmptyString(aField.Tag.Get(TagTypeName), aField.Name)]
+}
+
+// nestedTypeName returns anonymous struct field type name, package type name is qualified
+func nestedTypeName(aField *reflect.StructField, importsBuilder *strings.Builder, imports *importSet, opts *options) string {
+	typeName := firstNotEmptyString(aField.Tag.Get(TagTypeName), aField.Name)
+	if pkgType := opts.generateOption.getPackageType(typeName); pkgType != nil {
+		return appendImportIfNeeded(importsBuilder, firstNotEmptyString(pkgType.ImportPath, pkgType.Package), pkgType.Package, imports, false, opts) + "." + typeName
+	}
+	return typeName
+}
+
+// buildNestedType writes anonymous struct field type name and builds its type declaration
+func buildNestedType(mainBuilder *strings.Builder, importsBuilder *strings.Builder, aField *reflect.StructField, structType reflect.Type, imports *importSet, opts *options) []*strings.Builder {
+	typeName := firstNotEmptyString(aField.Tag.Get(TagTypeName), aField.Name)
+	if opts.generateOption.getPackageType(typeName) != nil {
+		mainBuilder.WriteString(nestedTypeName(aField, importsBuilder, imports, opts))
+		return nil
+	}
+	mainBuilder.WriteString(typeName)
+	skipTypeGeneration := false
+	if opts.skipFieldType != nil {
+		skipTypeGeneration = opts.skipFieldType(aField)
+	}
+	if strings.Contains(typeName, ".") || skipTypeGeneration {
+		_, name := splitPackage(typeName)
+		if aType := opts.getPackageType(name); aType != nil && !isLocalPackage(aType.Package, aType.ImportPath, opts.Package, opts.ImportPath) {
+			appendImportIfNeeded(importsBuilder, aType.ModulePath, aType.Package, imports, false, opts)
+		}
+		return nil
+	}
+	if opts.generateOption.buildTypes[typeName] {
+		return nil
+	}
+	opts.generateOption.buildTypes[typeName] = true
+	nestedStruct := &strings.Builder{}
+	nestedStruct.WriteString("type ")
+	nestedStruct.WriteString(typeName)
+	nestedStruct.WriteByte(' ')
+	return append([]*strings.Builder{nestedStruct}, buildGoType(nestedStruct, importsBuilder, typeName, structType, imports, opts)...)
 }
 
-func updateType(aType *Type, aField *reflect.StructField, opts *options, importsBuilder *strings.Builder, imports map[string]bool, isMain bool) {
+func updateType(aType *Type, aField *reflect.StructField, opts *options, importsBuilder *strings.Builder, imports *importSet) {
 	if aType == nil {
 		return
 	}
 	typeName := aType.TypeName()
-	if opts.Package == aType.Package {
+	if isLocalPackage(aType.Package, aType.ImportPath, opts.Package, opts.ImportPath) {
 		typeName = aType.SimpleTypeName()
+	} else if importPath := firstNotEmptyString(aType.ImportPath, aType.ModulePath); importPath != "" && aType.Package != "" {
+		qualifier := appendImportIfNeeded(importsBuilder, importPath, aType.Package, imports, false, opts)
+		typeName = qualifier + "." + aType.SimpleTypeName()
 	}
 	if typeName != "" {
 		aField.Tag += reflect.StructTag(" " + TagTypeName + `:"` + typeName + `"`)
 	}
-	typePkg, _ := splitPackage(typeName)
-	if typePkg != "" && typePkg != opts.Package && aType.ModulePath != "" {
-		appendImportIfNeeded(importsBuilder, aType.ModulePath, imports, isMain, opts)
-	}
 }
 
 func splitPackage(name string) (string, string) {
@@ -249,7 +430,7 @@ func splitPackage(name string) (string, string) {
 }
 
 func resolveType(aType reflect.Type, types *Types) *Type {
-	if types == nil || len(types.info) == 0 {
+	if types == nil {
 		return nil
 	}
 	rawType := aType
@@ -259,24 +440,24 @@ func resolveType(aType reflect.Type, types *Types) *Type {
 	if rawType.Kind() == reflect.Ptr {
 		rawType = rawType.Elem()
 	}
-	info, ok := types.info[rawType]
-	if !ok {
-		return nil
-	}
-	return info
+	return types.exactInfo(rawType) //structural match would rename unrelated anonymous struct
 }
 
-func appendImportIfNeeded(importsBuilder *strings.Builder, pkgPath string, imports map[string]bool, isMain bool, opts *options) {
-	if isMain {
-		return
+// appendImportIfNeeded appends import and returns package qualifier, colliding package names are imported with alias
+func appendImportIfNeeded(importsBuilder *strings.Builder, pkgPath string, pkgName string, imports *importSet, isMain bool, opts *options) string {
+	if isMain || pkgPath == "" {
+		return pkgName
 	}
-
-	if pkgPath == "" || imports[pkgPath] {
-		return
+	if imports.has(pkgPath) {
+		return imports.qualifier(pkgPath, pkgName)
 	}
-
-	imports[pkgPath] = true
-	importsBuilder.WriteString(`  "`)
+	qualifier := imports.qualifier(pkgPath, firstNotEmptyString(pkgName, path.Base(pkgPath)))
+	importsBuilder.WriteString(`  `)
+	if pkgName != "" && qualifier != pkgName {
+		importsBuilder.WriteString(qualifier)
+		importsBuilder.WriteByte(' ')
+	}
+	importsBuilder.WriteString(`"`)
 	if len(opts.importModule) > 0 {
 		if modulePath, ok := opts.importModule[pkgPath]; ok {
 			importsBuilder.WriteString(modulePath)
@@ -286,49 +467,7 @@ func appendImportIfNeeded(importsBuilder *strings.Builder, pkgPath string, impor
 	importsBuilder.WriteString(pkgPath)
 	importsBuilder.WriteByte('"')
 	importsBuilder.WriteByte('\n')
-}
-
-func appendElem(sb *strings.Builder, rType reflect.Type) reflect.Type {
-	switch rType.Kind() {
-	case reflect.Interface:
-		sb.WriteString("interface{}")
-	case reflect.Ptr:
-		sb.WriteByte('*')
-		rType = rType.Elem()
-		return appendElem(sb, rType)
-	case reflect.Slice:
-		sb.WriteString("[]")
-		rType = rType.Elem()
-		return appendElem(sb, rType)
-	case reflect.Array:
-		sb.WriteString("[]")
-		rType = rType.Elem()
-		return appendElem(sb, rType)
-	case reflect.Map:
-		sb.WriteString("map[")
-		keyType := rType.Key().Name()
-		if keyType == "" {
-			keyType = rType.Key().String()
-		}
-		sb.WriteString(keyType)
-		sb.WriteString("]")
-		rType = rType.Elem()
-		return appendElem(sb, rType)
-	}
-	return rType
-}
-
-func isFullyRenderedByAppendElem(rType reflect.Type) bool {
-	switch rType.Kind() {
-	case reflect.Interface:
-		return true
-	case reflect.Ptr, reflect.Slice, reflect.Array:
-		return isFullyRenderedByAppendElem(rType.Elem())
-	case reflect.Map:
-		return isFullyRenderedByAppendElem(rType.Elem())
-	default:
-		return false
-	}
+	return qualifier
 }
 
 func firstNotEmptyString(value ...string) string {
@@ -1,11 +1,20 @@
 package xreflect
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/xreflect/internal/testdata"
+	"go/parser"
+	goscanner "go/scanner"
+	htmltemplate "html/template"
 	"reflect"
+	"strings"
 	"testing"
+	textscanner "text/scanner"
+	texttemplate "text/template"
 	"time"
+	"unsafe"
 )
 
 func TestGenerateGoStruct(t *testing.T) {
@@ -232,6 +241,30 @@ type Foo struct {
 			name:     "GeneratedStruct",
 			expected: "package generated\n\ntype GeneratedStruct struct {\n\tXmap map[string]interface{} `json:\",omitempty\"`\n}\n",
 		},
+		{
+			description: "colliding package names",
+			rType: reflect.StructOf([]reflect.StructField{
+				{Name: "Text", Type: reflect.TypeOf(&texttemplate.Template{})},
+				{Name: "HTML", Type: reflect.TypeOf(&htmltemplate.Template{})},
+				{Name: "Position", Type: reflect.TypeOf(textscanner.Position{})},
+				{Name: "Mode", Type: reflect.TypeOf(goscanner.Mode(0))},
+				{Name: "Modes", Type: reflect.TypeOf([]goscanner.Mode{})},
+			}),
+			name:     "Foo",
+			expected: "package generated\n\nimport (\n\tgoscanner \"go/scanner\"\n\thtmltemplate \"html/template\"\n\t\"text/scanner\"\n\t\"text/template\"\n)\n\ntype Foo struct {\n\tText     *template.Template\n\tHTML     *htmltemplate.Template\n\tPosition scanner.Position\n\tMode     goscanner.Mode\n\tModes    []goscanner.Mode\n}\n",
+		},
+		{
+			description: "complex map keys and nested containers",
+			rType: reflect.StructOf([]reflect.StructField{
+				{Name: "ByDuration", Type: reflect.TypeOf(map[time.Duration]string{})},
+				{Name: "ByPair", Type: reflect.TypeOf(map[[2]int]string{})},
+				{Name: "ByKey", Type: reflect.TypeOf(map[struct{ ID int }]string{})},
+				{Name: "Matrix", Type: reflect.TypeOf([3][]float64{})},
+				{Name: "Nested", Type: reflect.TypeOf(map[string]map[string][]*struct{ ID int }{})},
+			}),
+			name:     "Foo",
+			expected: "package generated\n\nimport (\n\t\"time\"\n)\n\ntype Foo struct {\n\tByDuration map[time.Duration]string\n\tByPair     map[[2]int]string\n\tByKey      map[struct{ ID int }]string\n\tMatrix     [3][]float64\n\tNested     map[string]map[string][]*Nested\n}\n\ntype Nested struct {\n\tID int\n}\n",
+		},
 	}
 
 	//for _, testCase := range testcases[len(testcases)-1:] {
@@ -243,3 +276,256 @@ type Foo struct {
 	}
 
 }
+
+func TestGenerateStruct_RegistryImportAliases(t *testing.T) {
+	userType := reflect.StructOf([]reflect.StructField{{Name: "Login", Type: StringType}})
+	accountType := reflect.StructOf([]reflect.StructField{{Name: "Number", Type: IntType}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("github.com/a/model.User", WithReflectType(userType)))
+	assert.Nil(t, types.Register("github.com/b/model.Account", WithReflectType(accountType)))
+	rType := reflect.StructOf([]reflect.StructField{
+		{Name: "User", Type: reflect.PtrTo(userType)},
+		{Name: "Accounts", Type: reflect.SliceOf(accountType)},
+	})
+	expected := `package api
+
+import (
+	"github.com/a/model"
+	bmodel "github.com/b/model"
+)
+
+type Session struct {
+	User     *model.User
+	Accounts []bmodel.Account
+}
+`
+	assert.Equal(t, expected, GenerateStruct("Session", rType, WithRegistry(types), WithPackage("api")))
+}
+
+type registryAudit struct {
+	ID int
+}
+
+func TestGenerateStruct_RegistryImportPath(t *testing.T) {
+	userType := reflect.StructOf([]reflect.StructField{{Name: "Login", Type: StringType}})
+	accountType := reflect.StructOf([]reflect.StructField{{Name: "Number", Type: IntType}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("github.com/a/model.User", WithReflectType(userType)))
+	assert.Nil(t, types.Register("github.com/b/model.Account", WithReflectType(accountType)))
+	rType := reflect.StructOf([]reflect.StructField{
+		{Name: "User", Type: reflect.PtrTo(userType)},
+		{Name: "Accounts", Type: reflect.SliceOf(accountType)},
+	})
+	expected := `package model
+
+import (
+	"github.com/b/model"
+)
+
+type Session struct {
+	User     *User
+	Accounts []model.Account
+}
+
+type User struct {
+	Login string
+}
+`
+	assert.Equal(t, expected, GenerateStruct("Session", rType, WithRegistry(types), WithPackage("model"), WithImportPath("github.com/a/model")))
+}
+
+func TestGenerateStruct_RegistryExactType(t *testing.T) {
+	types := NewTypes()
+	assert.Nil(t, types.Register("github.com/a/model.Audit", WithReflectType(reflect.TypeOf(registryAudit{}))))
+	metaType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	rType := reflect.StructOf([]reflect.StructField{{Name: "Meta", Type: reflect.PtrTo(metaType)}})
+	expected := `package api
+
+type Session struct {
+	Meta *Meta
+}
+
+type Meta struct {
+	ID int
+}
+`
+	assert.Equal(t, expected, GenerateStruct("Session", rType, WithRegistry(types), WithPackage("api")))
+}
+
+func TestGenerateStruct_Accessors(t *testing.T) {
+	rType := reflect.TypeOf(struct {
+		ID     int
+		Name   *string `json:"name" accessor:"readonly"`
+		Secret string  `accessor:"-"`
+		Audit  struct{ By string }
+		hidden int
+	}{})
+	testCases := []struct {
+		description string
+		options     []Option
+		expected    string
+	}{
+		{
+			description: "getters",
+			options:     []Option{WithGetters(true)},
+			expected:    "package generated\n\ntype Foo struct {\n\tID     int\n\tName   *string `json:\"name\"`\n\tSecret string\n\tAudit  Audit\n\thidden int\n}\n\n// GetID returns ID, zero value for nil receiver\nfunc (f *Foo) GetID() (ret int) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.ID\n}\n\n// GetName returns Name, zero value for nil receiver\nfunc (f *Foo) GetName() (ret *string) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.Name\n}\n\n// GetAudit returns Audit, zero value for nil receiver\nfunc (f *Foo) GetAudit() (ret Audit) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.Audit\n}\n\ntype Audit struct {\n\tBy string\n}\n\n// GetBy returns By, zero value for nil receiver\nfunc (a *Audit) GetBy() (ret string) {\n\tif a == nil {\n\t\treturn ret\n\t}\n\treturn a.By\n}\n",
+		},
+		{
+			description: "getters, setters and presence",
+			options:     []Option{WithGetters(true), WithSetters(true), WithPresence(true)},
+			expected:    "package generated\n\ntype Foo struct {\n\tID     int\n\tName   *string `json:\"name\"`\n\tSecret string\n\tAudit  Audit\n\thidden int\n\thas    [1]uint64\n}\n\n// GetID returns ID, zero value for nil receiver\nfunc (f *Foo) GetID() (ret int) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.ID\n}\n\n// SetID sets ID and marks it as set\nfunc (f *Foo) SetID(value int) {\n\tf.ID = value\n\tf.has[0] |= 1 << 0\n}\n\n// HasID returns true if ID was set\nfunc (f *Foo) HasID() bool {\n\treturn f != nil && f.has[0]&(1<<0) != 0\n}\n\n// GetName returns Name, zero value for nil receiver\nfunc (f *Foo) GetName() (ret *string) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.Name\n}\n\n// GetAudit returns Audit, zero value for nil receiver\nfunc (f *Foo) GetAudit() (ret Audit) {\n\tif f == nil {\n\t\treturn ret\n\t}\n\treturn f.Audit\n}\n\n// SetAudit sets Audit and marks it as set\nfunc (f *Foo) SetAudit(value Audit) {\n\tf.Audit = value\n\tf.has[0] |= 1 << 1\n}\n\n// HasAudit returns true if Audit was set\nfunc (f *Foo) HasAudit() bool {\n\treturn f != nil && f.has[0]&(1<<1) != 0\n}\n\ntype Audit struct {\n\tBy  string\n\thas [1]uint64\n}\n\n// GetBy returns By, zero value for nil receiver\nfunc (a *Audit) GetBy() (ret string) {\n\tif a == nil {\n\t\treturn ret\n\t}\n\treturn a.By\n}\n\n// SetBy sets By and marks it as set\nfunc (a *Audit) SetBy(value string) {\n\ta.By = value\n\ta.has[0] |= 1 << 0\n}\n\n// HasBy returns true if By was set\nfunc (a *Audit) HasBy() bool {\n\treturn a != nil && a.has[0]&(1<<0) != 0\n}\n",
+		},
+	}
+	for _, testCase := range testCases {
+		actual := GenerateStruct("Foo", rType, testCase.options...)
+		assert.Equal(t, testCase.expected, actual, testCase.description)
+	}
+	presenceType := reflect.TypeOf(struct {
+		ID  int
+		has bool
+	}{})
+	expected := "package generated\n\ntype Foo struct {\n\tID   int\n\thas  bool\n\thas1 [1]uint64\n}\n\n// SetID sets ID and marks it as set\nfunc (f *Foo) SetID(value int) {\n\tf.ID = value\n\tf.has1[0] |= 1 << 0\n}\n\n// HasID returns true if ID was set\nfunc (f *Foo) HasID() bool {\n\treturn f != nil && f.has1[0]&(1<<0) != 0\n}\n"
+	assert.Equal(t, expected, GenerateStruct("Foo", presenceType, WithPresence(true)), "presence implies setters, presence field does not collide")
+}
+
+func TestGenerateStruct_Constructors(t *testing.T) {
+	rType := reflect.TypeOf(struct {
+		Name    string        `default:"abc"`
+		Ratio   *float64      `default:"1.5"`
+		Timeout time.Duration `default:"5s"`
+		Active  bool          `default:"true"`
+		Audit   struct {
+			By string `default:"system"`
+		}
+		Meta *struct{ Version uint8 }
+	}{})
+	expected := "package generated\n\nimport (\n\t\"time\"\n)\n\ntype Foo struct {\n\tName    string        `default:\"abc\"`\n\tRatio   *float64      `default:\"1.5\"`\n\tTimeout time.Duration `default:\"5s\"`\n\tActive  bool          `default:\"true\"`\n\tAudit   Audit\n\tMeta    *Meta\n}\n\n// NewFoo returns Foo with defaults applied\nfunc NewFoo() *Foo {\n\tret := &Foo{}\n\tret.Init()\n\treturn ret\n}\n\n// Init sets zero value fields to their defaults\nfunc (f *Foo) Init() {\n\tif f.Name == \"\" {\n\t\tf.Name = \"abc\"\n\t}\n\tif f.Ratio == nil {\n\t\tvalue := float64(1.5)\n\t\tf.Ratio = &value\n\t}\n\tif f.Timeout == 0 {\n\t\tf.Timeout = 5000000000\n\t}\n\tif !f.Active {\n\t\tf.Active = true\n\t}\n\tf.Audit.Init()\n\tif f.Meta != nil {\n\t\tf.Meta.Init()\n\t}\n}\n\ntype Audit struct {\n\tBy string `default:\"system\"`\n}\n\n// NewAudit returns Audit with defaults applied\nfunc NewAudit() *Audit {\n\tret := &Audit{}\n\tret.Init()\n\treturn ret\n}\n\n// Init sets zero value fields to their defaults\nfunc (a *Audit) Init() {\n\tif a.By == \"\" {\n\t\ta.By = \"system\"\n\t}\n}\n\ntype Meta struct {\n\tVersion uint8\n}\n\n// NewMeta returns Meta with defaults applied\nfunc NewMeta() *Meta {\n\tret := &Meta{}\n\tret.Init()\n\treturn ret\n}\n\n// Init sets zero value fields to their defaults\nfunc (m *Meta) Init() {\n}\n"
+	assert.Equal(t, expected, GenerateStruct("Foo", rType, WithConstructors(true)))
+}
+
+func TestGenerateStruct_DeepCopyEqual(t *testing.T) {
+	rType := reflect.TypeOf(struct {
+		Tags    []string
+		Created *time.Time
+		Attrs   map[string][]int
+		Items   []*struct{ Name string }
+	}{})
+	expected := "package generated\n\nimport (\n\t\"time\"\n)\n\ntype Foo struct {\n\tTags    []string\n\tCreated *time.Time\n\tAttrs   map[string][]int\n\tItems   []*Items\n}\n\n// Clone returns deep copy of Foo\nfunc (f *Foo) Clone() *Foo {\n\treturn f.clone(map[interface{}]interface{}{})\n}\n\n// DeepCopyInto deep copies Foo into dest\nfunc (f *Foo) DeepCopyInto(dest *Foo) {\n\tf.deepCopyInto(dest, map[interface{}]interface{}{f: dest})\n}\n\nfunc (f *Foo) clone(visited map[interface{}]interface{}) *Foo {\n\tif f == nil {\n\t\treturn nil\n\t}\n\tif ret, ok := visited[f]; ok {\n\t\treturn ret.(*Foo)\n\t}\n\tret := &Foo{}\n\tvisited[f] = ret\n\tf.deepCopyInto(ret, visited)\n\treturn ret\n}\n\nfunc (f *Foo) deepCopyInto(dest *Foo, visited map[interface{}]interface{}) {\n\t*dest = *f\n\tif f.Tags != nil {\n\t\tdest.Tags = make([]string, len(f.Tags))\n\t\tcopy(dest.Tags, f.Tags)\n\t}\n\tif f.Created != nil {\n\t\tdest.Created = new(time.Time)\n\t\t*dest.Created = *f.Created\n\t}\n\tif f.Attrs != nil {\n\t\tdest.Attrs = make(map[string][]int, len(f.Attrs))\n\t\tfor k0, v0 := range f.Attrs {\n\t\t\tc0 := v0\n\t\t\tif v0 != nil {\n\t\t\t\tc0 = make([]int, len(v0))\n\t\t\t\tcopy(c0, v0)\n\t\t\t}\n\t\t\tdest.Attrs[k0] = c0\n\t\t}\n\t}\n\tif f.Items != nil {\n\t\tdest.Items = make([]*Items, len(f.Items))\n\t\tcopy(dest.Items, f.Items)\n\t\tfor i0 := range f.Items {\n\t\t\tif f.Items[i0] != nil {\n\t\t\t\tdest.Items[i0] = f.Items[i0].clone(visited)\n\t\t\t}\n\t\t}\n\t}\n}\n\n// Equal returns true if Foo is deeply equal to other\nfunc (f *Foo) Equal(other *Foo) bool {\n\treturn f.equal(other, map[[2]interface{}]bool{})\n}\n\nfunc (f *Foo) equal(other *Foo, visited map[[2]interface{}]bool) bool {\n\tif f == nil || other == nil {\n\t\treturn f == other\n\t}\n\tkey := [2]interface{}{f, other}\n\tif visited[key] {\n\t\treturn true\n\t}\n\tvisited[key] = true\n\tif len(f.Tags) != len(other.Tags) {\n\t\treturn false\n\t}\n\tfor i0 := range f.Tags {\n\t\tif f.Tags[i0] != other.Tags[i0] {\n\t\t\treturn false\n\t\t}\n\t}\n\tif (f.Created == nil) != (other.Created == nil) {\n\t\treturn false\n\t}\n\tif f.Created != nil {\n\t\tif !(*f.Created).Equal((*other.Created)) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif len(f.Attrs) != len(other.Attrs) {\n\t\treturn false\n\t}\n\tfor k0, v0 := range f.Attrs {\n\t\tw0, ok := other.Attrs[k0]\n\t\tif !ok {\n\t\t\treturn false\n\t\t}\n\t\tif len(v0) != len(w0) {\n\t\t\treturn false\n\t\t}\n\t\tfor i1 := range v0 {\n\t\t\tif v0[i1] != w0[i1] {\n\t\t\t\treturn false\n\t\t\t}\n\t\t}\n\t}\n\tif len(f.Items) != len(other.Items) {\n\t\treturn false\n\t}\n\tfor i0 := range f.Items {\n\t\tif !f.Items[i0].equal(other.Items[i0], visited) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\ntype Items struct {\n\tName string\n}\n\n// Clone returns deep copy of Items\nfunc (i *Items) Clone() *Items {\n\treturn i.clone(map[interface{}]interface{}{})\n}\n\n// DeepCopyInto deep copies Items into dest\nfunc (i *Items) DeepCopyInto(dest *Items) {\n\ti.deepCopyInto(dest, map[interface{}]interface{}{i: dest})\n}\n\nfunc (i *Items) clone(visited map[interface{}]interface{}) *Items {\n\tif i == nil {\n\t\treturn nil\n\t}\n\tif ret, ok := visited[i]; ok {\n\t\treturn ret.(*Items)\n\t}\n\tret := &Items{}\n\tvisited[i] = ret\n\ti.deepCopyInto(ret, visited)\n\treturn ret\n}\n\nfunc (i *Items) deepCopyInto(dest *Items, visited map[interface{}]interface{}) {\n\t*dest = *i\n}\n\n// Equal returns true if Items is deeply equal to other\nfunc (i *Items) Equal(other *Items) bool {\n\treturn i.equal(other, map[[2]interface{}]bool{})\n}\n\nfunc (i *Items) equal(other *Items, visited map[[2]interface{}]bool) bool {\n\tif i == nil || other == nil {\n\t\treturn i == other\n\t}\n\tkey := [2]interface{}{i, other}\n\tif visited[key] {\n\t\treturn true\n\t}\n\tvisited[key] = true\n\tif i.Name != other.Name {\n\t\treturn false\n\t}\n\treturn true\n}\n"
+	assert.Equal(t, expected, GenerateStruct("Foo", rType, WithDeepCopy(true), WithEqual(true)))
+
+	embedded := GenerateStruct("Foo", reflect.TypeOf(struct {
+		*time.Time
+		Name string
+	}{}), WithDeepCopy(true), WithEqual(true))
+	assert.Contains(t, embedded, "dest.Time = new(time.Time)\n\t\t*dest.Time = *f.Time\n")
+	assert.Contains(t, embedded, "if !(*f.Time).Equal((*other.Time)) {\n")
+
+	nested := GenerateStruct("Foo", reflect.TypeOf(struct {
+		Stamp struct {
+			At time.Time
+			N  int
+		} `typeName:"Stamp"`
+		Node *testdata.Node
+	}{}), WithEqual(true))
+	assert.Contains(t, nested, "if !f.Stamp.equal(&other.Stamp, visited) {\n")
+	assert.Contains(t, nested, "if !s.At.Equal(other.At) {\n")
+	assert.Contains(t, nested, "if !xreflect.DeepEqual((*f.Node), (*other.Node)) {\n")
+
+	dynamic := GenerateStruct("Foo", reflect.TypeOf(struct {
+		Any interface{}
+		Err error
+	}{}), WithDeepCopy(true))
+	assert.Contains(t, dynamic, "\"github.com/viant/xreflect\"")
+	assert.Contains(t, dynamic, "dest.Any = xreflect.DeepCopy(f.Any)\n")
+	assert.Contains(t, dynamic, "dest.Err = xreflect.DeepCopy(f.Err).(error)\n")
+}
+
+func TestGenerateStruct_Docs(t *testing.T) {
+	types, err := ParseTypes("./internal/testdata/doc", WithParserMode(parser.ParseComments))
+	if !assert.Nil(t, err) {
+		return
+	}
+	rType, err := types.Type("Account")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, reflect.StructTag(`json:"id"`), rType.Field(0).Tag) //comments do not change parsed type
+	testCases := []struct {
+		description string
+		options     []Option
+		expected    string
+	}{
+		{
+			description: "header, build constraint, type and field docs",
+			options:     []Option{WithPackage("doc"), WithGeneratedHeader(true), WithBuildConstraint("linux && !race"), WithTypeDoc(types.TypeDoc("Account")), WithFieldDoc(types.FieldDoc)},
+			expected:    "// Code generated by xreflect. DO NOT EDIT.\n\n//go:build linux && !race\n// +build linux,!race\n\npackage doc\n\n// Account represents user account\n//\n// It is used to test generated doc comments.\ntype Account struct {\n\t// ID is account identifier\n\tID int `json:\"id\"`\n\t// Name is display name\n\tName string\n\t// primary email\n\tEmail string `json:\"email\" description:\"primary email\"`\n\tNote  string\n}\n",
+		},
+		{
+			description: "field documentation callback",
+			options: []Option{WithPackage("doc"), WithFieldDoc(types.FieldDoc), WithOnStructField(func(field *reflect.StructField, tag, typeName, documentation *string) {
+				if field.Name == "Note" {
+					*documentation = "Note is free text"
+				}
+			})},
+			expected: "package doc\n\ntype Account struct {\n\t// ID is account identifier\n\tID int `json:\"id\"`\n\t// Name is display name\n\tName string\n\t// primary email\n\tEmail string `json:\"email\" description:\"primary email\"`\n\t// Note is free text\n\tNote string\n}\n",
+		},
+		{
+			description: "source comments without field doc",
+			options:     []Option{WithPackage("doc")},
+			expected:    "package doc\n\ntype Account struct {\n\tID   int `json:\"id\"`\n\tName string\n\t// primary email\n\tEmail string `json:\"email\" description:\"primary email\"`\n\tNote  string\n}\n",
+		},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, GenerateStruct("Account", rType, testCase.options...), testCase.description)
+	}
+}
+
+func TestGenerateStructTo(t *testing.T) {
+	valid := reflect.TypeOf(struct {
+		ID      int `json:"id"`
+		Created *time.Time
+		Handler func(string) error
+		Items   []struct{ Name string }
+	}{})
+	testCases := []struct {
+		description string
+		name        string
+		rType       reflect.Type
+		options     []Option
+		expectErr   error
+		expectMsg   string
+	}{
+		{description: "valid struct", name: "Foo", rType: valid, options: []Option{WithJSONCodec(true), WithGetters(true)}},
+		{description: "invalid type name", name: "1Foo", rType: valid, expectErr: ErrInvalidIdentifier, expectMsg: `invalid identifier: type "1Foo"`},
+		{description: "invalid package name", name: "Foo", rType: valid, options: []Option{WithPackage("my-pkg")}, expectErr: ErrInvalidIdentifier, expectMsg: `invalid identifier: package "my-pkg"`},
+		{description: "chan of imported type", name: "Foo", rType: reflect.TypeOf(struct{ Events chan *time.Time }{}), expectErr: ErrUnsupportedType, expectMsg: "unsupported type: Foo.Events (chan *time.Time)"},
+		{description: "nested unsafe pointer", name: "Foo", rType: reflect.TypeOf(struct {
+			Items []struct{ Ptr unsafe.Pointer }
+		}{}), expectErr: ErrUnsupportedType, expectMsg: "unsupported type: Foo.Items.Ptr (unsafe.Pointer)"},
+		{description: "unexported type of other package", name: "Foo", rType: reflect.TypeOf(struct{ Item deepCopyItem }{}), expectErr: ErrUnsupportedType, expectMsg: "unsupported type: Foo.Item (xreflect.deepCopyItem)"},
+		{description: "unexported type of package with the same name", name: "Foo", rType: reflect.TypeOf(struct{ Item deepCopyItem }{}), options: []Option{WithPackage("xreflect"), WithImportPath("github.com/acme/xreflect")}, expectErr: ErrUnsupportedType, expectMsg: "unsupported type: Foo.Item (xreflect.deepCopyItem)"},
+		{description: "unexported type of generated package", name: "Foo", rType: reflect.TypeOf(struct{ Item deepCopyItem }{}), options: []Option{WithPackage("xreflect"), WithImportPath("github.com/viant/xreflect")}},
+		{description: "invalid source", name: "Foo", rType: valid, options: []Option{WithSnippetBefore("func {")}, expectErr: ErrInvalidSource, expectMsg: "source: func {"},
+		{description: "invalid build constraint", name: "Foo", rType: valid, options: []Option{WithBuildConstraint("linux &&")}, expectErr: ErrInvalidBuildConstraint, expectMsg: `invalid build constraint: "linux &&"`},
+		{description: "invalid default", name: "Foo", rType: reflect.TypeOf(struct {
+			Audit struct {
+				Retries int `default:"x"`
+			}
+		}{}), options: []Option{WithConstructors(true)}, expectErr: ErrInvalidDefault, expectMsg: `invalid default: field Foo.Audit.Retries: strconv.ParseInt: parsing "x": invalid syntax`},
+	}
+	for _, testCase := range testCases {
+		writer := &strings.Builder{}
+		err := GenerateStructTo(writer, testCase.name, testCase.rType, testCase.options...)
+		if testCase.expectErr != nil {
+			assert.True(t, errors.Is(err, testCase.expectErr), testCase.description)
+			if assert.NotNil(t, err, testCase.description) {
+				assert.Contains(t, err.Error(), testCase.expectMsg, testCase.description)
+			}
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, GenerateStruct(testCase.name, testCase.rType, testCase.options...), writer.String(), testCase.description)
+	}
+}
@@ -0,0 +1,160 @@
+package xreflect
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeneratedFile represents generated source syntax tree, it can be modified and printed with go/printer
+type GeneratedFile struct {
+	FileSet *token.FileSet
+	File    *ast.File
+}
+
+// GenerateStructAST returns generated struct source syntax tree, invalid identifiers, unsupported types, invalid defaults and source that does not parse are reported as GenerateError
+func GenerateStructAST(name string, structType reflect.Type, opts ...Option) (*GeneratedFile, error) {
+	genOptions := &options{}
+	genOptions.Apply(opts...)
+	genOptions.initGen()
+	if err := validateGenerate(name, structType, genOptions); err != nil {
+		return nil, err
+	}
+	return parseGenerated(name, generateStruct(name, structType, genOptions), token.NewFileSet())
+}
+
+// parseGenerated parses generated source with supplied file set
+func parseGenerated(name string, source string, fileSet *token.FileSet) (*GeneratedFile, error) {
+	file, err := parser.ParseFile(fileSet, name+".go", source, parser.ParseComments|parser.AllErrors)
+	if err != nil {
+		return nil, newSourceError(name, source, err)
+	}
+	return &GeneratedFile{FileSet: fileSet, File: file}, nil
+}
+
+// newSourceError returns GenerateError with offending generated source line
+func newSourceError(name string, source string, err error) error {
+	ret := &GenerateError{Err: ErrInvalidSource, Name: name, Cause: err}
+	var errorList scanner.ErrorList
+	if errors.As(err, &errorList) && len(errorList) > 0 {
+		lines := strings.Split(source, "\n")
+		if line := errorList[0].Pos.Line; line > 0 && line <= len(lines) {
+			ret.Line = lines[line-1]
+		}
+	}
+	return ret
+}
+
+// Decls returns generated declarations other than imports
+func (f *GeneratedFile) Decls() []ast.Decl {
+	var result []ast.Decl
+	for _, decl := range f.File.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+		result = append(result, decl)
+	}
+	return result
+}
+
+// Merge appends other file declarations with their comments and missing imports, merged source is parsed again with file set
+func (f *GeneratedFile) Merge(other *GeneratedFile) error {
+	if f.File.Name.Name != other.File.Name.Name {
+		return fmt.Errorf("failed to merge package %v into %v", other.File.Name.Name, f.File.Name.Name)
+	}
+	var missing []*ast.ImportSpec
+	for _, spec := range other.File.Imports {
+		imported, err := f.missingImport(spec)
+		if err != nil {
+			return err
+		}
+		if imported != nil {
+			missing = append(missing, imported)
+		}
+	}
+	for _, imported := range missing {
+		f.addImport(imported)
+	}
+	source := &bytes.Buffer{}
+	if err := f.Format(source); err != nil {
+		return err
+	}
+	for _, decl := range other.Decls() { //decl positions belong to other file set, so decls are merged as source
+		source.WriteString("\n")
+		if err := format.Node(source, other.FileSet, &printer.CommentedNode{Node: decl, Comments: other.File.Comments}); err != nil {
+			return err
+		}
+		source.WriteString("\n")
+	}
+	name := strings.TrimSuffix(f.FileSet.File(f.File.Pos()).Name(), ".go")
+	merged, err := parseGenerated(name, source.String(), f.FileSet)
+	if err != nil {
+		return err
+	}
+	f.File = merged.File
+	return nil
+}
+
+// missingImport returns import spec copy unless already imported, import qualifier bound to other path is reported as ErrAmbiguousPackage
+func (f *GeneratedFile) missingImport(spec *ast.ImportSpec) (*ast.ImportSpec, error) {
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	qualifier := importQualifier(spec, importPath)
+	for _, candidate := range f.File.Imports {
+		candidatePath, _ := strconv.Unquote(candidate.Path.Value)
+		candidateQualifier := importQualifier(candidate, candidatePath)
+		if candidatePath == importPath && candidateQualifier == qualifier {
+			return nil, nil
+		}
+		if candidateQualifier == qualifier {
+			return nil, fmt.Errorf("failed to import %v as %v: %w", importPath, qualifier, ErrAmbiguousPackage)
+		}
+	}
+	imported := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: spec.Path.Value}}
+	if spec.Name != nil {
+		imported.Name = ast.NewIdent(spec.Name.Name)
+	}
+	return imported, nil
+}
+
+// addImport appends import spec to leading import declaration
+func (f *GeneratedFile) addImport(imported *ast.ImportSpec) {
+	var importDecl *ast.GenDecl
+	if len(f.File.Decls) > 0 {
+		if genDecl, ok := f.File.Decls[0].(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			importDecl = genDecl
+		}
+	}
+	if importDecl == nil {
+		importDecl = &ast.GenDecl{Tok: token.IMPORT, Lparen: f.File.Name.End(), Rparen: f.File.Name.End()}
+		f.File.Decls = append([]ast.Decl{importDecl}, f.File.Decls...)
+	}
+	importDecl.Specs = append(importDecl.Specs, imported)
+	f.File.Imports = append(f.File.Imports, imported)
+}
+
+func importQualifier(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return path.Base(importPath)
+}
+
+// Format writes gofmt formatted source with go/printer, comments are sorted by position
+func (f *GeneratedFile) Format(w io.Writer) error {
+	sort.SliceStable(f.File.Comments, func(i, j int) bool {
+		return f.File.Comments[i].Pos() < f.File.Comments[j].Pos()
+	})
+	return format.Node(w, f.FileSet, f.File)
+}
@@ -0,0 +1,56 @@
+package xreflect
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateStructAST(t *testing.T) {
+	foo, err := GenerateStructAST("Foo", reflect.TypeOf(struct {
+		ID      int
+		Created time.Time
+	}{}), WithGeneratedHeader(true))
+	if !assert.Nil(t, err) {
+		return
+	}
+	bar, err := GenerateStructAST("Bar", reflect.TypeOf(struct {
+		Name    string
+		Updated *time.Time
+		Tags    []string
+	}{}), WithGetters(true), WithImports([]string{"strings"}), WithSnippetAfter("\n\nvar _ = strings.ToLower"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, len(foo.Decls()))
+	assert.Equal(t, 5, len(bar.Decls()))
+	if !assert.Nil(t, foo.Merge(bar)) {
+		return
+	}
+
+	fooDecl := foo.Decls()[0].(*ast.GenDecl)
+	comment := &ast.CommentGroup{List: []*ast.Comment{{Slash: fooDecl.Pos() - 1, Text: "// Foo represents merged type"}}}
+	fooDecl.Doc = comment
+	foo.File.Comments = append(foo.File.Comments, comment)
+	builder := &strings.Builder{}
+	if !assert.Nil(t, foo.Format(builder)) {
+		return
+	}
+	expected := "// Code generated by xreflect. DO NOT EDIT.\n\npackage generated\n\nimport (\n\t\"strings\"\n\t\"time\"\n)\n\n// Foo represents merged type\ntype Foo struct {\n\tID      int\n\tCreated time.Time\n}\n\ntype Bar struct {\n\tName    string\n\tUpdated *time.Time\n\tTags    []string\n}\n\n// GetName returns Name, zero value for nil receiver\nfunc (b *Bar) GetName() (ret string) {\n\tif b == nil {\n\t\treturn ret\n\t}\n\treturn b.Name\n}\n\n// GetUpdated returns Updated, zero value for nil receiver\nfunc (b *Bar) GetUpdated() (ret *time.Time) {\n\tif b == nil {\n\t\treturn ret\n\t}\n\treturn b.Updated\n}\n\n// GetTags returns Tags, zero value for nil receiver\nfunc (b *Bar) GetTags() (ret []string) {\n\tif b == nil {\n\t\treturn ret\n\t}\n\treturn b.Tags\n}\n\nvar _ = strings.ToLower\n"
+	assert.Equal(t, expected, builder.String())
+
+	other, err := GenerateStructAST("Other", reflect.TypeOf(struct{ ID int }{}), WithPackage("other"))
+	if assert.Nil(t, err) {
+		assert.NotNil(t, foo.Merge(other), "merge of other package")
+	}
+	conflicting, err := GenerateStructAST("Conflicting", reflect.TypeOf(struct{ ID int }{}), WithImports([]string{"example.com/time"}), WithSnippetAfter("\n\nvar _ = time.Now"))
+	if assert.Nil(t, err) {
+		assert.True(t, errors.Is(foo.Merge(conflicting), ErrAmbiguousPackage), "merge of conflicting import")
+	}
+	_, err = GenerateStructAST("Invalid", reflect.TypeOf(struct{ ID int }{}), WithSnippetBefore("func {"))
+	assert.True(t, errors.Is(err, ErrInvalidSource))
+}
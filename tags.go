@@ -2,4 +2,14 @@ package xreflect
 
 const (
 	TagTypeName = "typeName"
+	//TagPlaceholder marks interface{} field standing in for a type that could not be expressed, value is a placeholder reason
+	TagPlaceholder = "placeholder"
+	//TagAccessor controls generated field accessors, "-" skips field, "readonly" skips setter
+	TagAccessor = "accessor"
+	//TagDefault defines field default value applied by ApplyDefaults and generated Init methods
+	TagDefault = "default"
+	//TagDoc holds field documentation, it is generated as field doc comment, parsed source comments are available with DirTypes.FieldDoc
+	TagDoc = "doc"
+	//TagDescription holds field description, it is generated as field doc comment unless doc tag is defined
+	TagDescription = "description"
 )
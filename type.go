@@ -1,8 +1,9 @@
 package xreflect
 
 import (
-	"fmt"
 	"go/ast"
+	"go/parser"
+	"path"
 	"reflect"
 	"strings"
 )
@@ -12,16 +13,32 @@ const customPackageName = "PackageName"
 type Type struct {
 	PackagePath string
 	ModulePath  string
+	ImportPath  string
 	Package     string
 	Name        string
 	KeyName     string
 	KeyType     reflect.Type
 	Definition  string
-	Type        reflect.Type
-	Methods     []reflect.Method
-	Registry    *Types
-	IsPtr       bool
-	Imports     GoImports
+	//Doc is type documentation, it is written as generated type doc comment
+	Doc      string
+	Type     reflect.Type
+	Methods  []reflect.Method
+	Registry *Types
+	IsPtr    bool
+	Imports  GoImports
+	//parse holds parse options supplied with the type, they are applied when type is loaded from PackagePath
+	parse parseFlags
+}
+
+// parseFlags represents parse options changing types parsed from type location
+type parseFlags struct {
+	mode        parser.Mode
+	breakCycles bool
+	lenient     bool
+}
+
+func (f parseFlags) options() []Option {
+	return []Option{WithParserMode(f.mode), WithBreakCycles(f.breakCycles), WithLenient(f.lenient)}
 }
 
 // TypeName package qualified type name
@@ -37,6 +54,14 @@ func (t *Type) TypeName() string {
 	return t.Package + "." + t.Name
 }
 
+// packageKey returns registry package key, import path if known, package name otherwise
+func (t *Type) packageKey() string {
+	if t.ImportPath != "" {
+		return t.ImportPath
+	}
+	return t.Package
+}
+
 func (t *Type) IsLoadable() bool {
 	return t.Definition != "" || t.PackagePath != ""
 }
@@ -52,10 +77,23 @@ func (t *Type) LoadType(registry *Types) (reflect.Type, error) {
 	name := rawName(t.Name)
 
 	if t.PackagePath != "" {
-		pkg := registry.ensurePackage(t.Package, t.PackagePath)
-
-		if pkg.dirType == nil {
-			dirType, err := ParseTypes(t.PackagePath, WithTypeLookup(t.Registry.Lookup))
+		var pkg *Package
+		if pkg, err = registry.ensurePackage(t.packageKey(), t.PackagePath); err != nil {
+			return nil, err
+		}
+		pkgDirType := pkg.sourceTypes()
+		if pkgDirType == nil {
+			var cacheKey string
+			parseOptions := append([]Option{WithTypeLookup(t.Registry.Lookup)}, t.parse.options()...)
+			if registry.cacheDir != "" {
+				if cacheKey = registry.cacheKey(t.PackagePath, parseOptions); cacheKey != "" {
+					if cachedPkg, rType := registry.loadCache(cacheKey, t, pkg); rType != nil {
+						t.Package, t.ImportPath = cachedPkg.Name, cachedPkg.ImportPath
+						return rType, nil
+					}
+				}
+			}
+			dirType, err := ParseTypes(t.PackagePath, parseOptions...)
 			if err != nil {
 				return nil, err
 			}
@@ -72,44 +110,54 @@ func (t *Type) LoadType(registry *Types) (reflect.Type, error) {
 					}
 				}
 			}
-			if packageName != "" && packageName != pkg.Name { //otherwise correct it
-				pkg.packagePaths[t.PackagePath] = packageName
-				pkg.Path = ""
-				pkg = registry.ensurePackage(packageName, t.PackagePath)
+			if packageName == "" {
+				packageName = pkg.Name
+			}
+			if pkg, err = registry.importPackage(dirType.ModulePath, packageName, t.PackagePath); err != nil { //ensure package is keyed by its import path
+				return nil, err
+			}
+			pkgDirType = pkg.setDirType(dirType)
+			if cacheKey != "" {
+				_ = registry.storeCache(cacheKey, pkg.Name, dirType)
 			}
-			pkg.dirType = dirType
 		} else {
-			rType, err = pkg.dirType.Type(name)
+			rType, err = pkgDirType.Type(name)
 			if err != nil {
 				return nil, err
 			}
 		}
-		t.Package = pkg.Name
-		if methods := pkg.dirType.Methods(name); len(methods) > 0 {
+		t.Package, t.ImportPath = pkg.Name, pkg.ImportPath
+		if methods := pkgDirType.Methods(name); len(methods) > 0 {
+			pkg.mux.Lock()
 			for _, item := range methods {
 				method := AsMethod(item)
 				pkg.methods[t.Name] = append(pkg.methods[t.Name], method)
 				t.Methods = append(t.Methods, method)
 			}
+			pkg.mux.Unlock()
 		}
 		return rType, nil
 	}
 	if t.Definition != "" {
 		return Parse(t.Definition, WithRegistry(t.Registry), WithPackage(t.Package), WithGoImports(t.Imports))
 	}
-	return nil, fmt.Errorf("unable to load type: %v", t.TypeName())
+	return nil, newTypeError(ErrTypeNotFound, t.Name, t.Package, t.PackagePath)
 }
 
 func AsMethod(item *ast.FuncDecl) reflect.Method {
 	methodName, _ := Node{item.Name}.Stringify()
-	method := reflect.Method{
-		Name:    methodName,
+	return newMethod(methodName)
+}
+
+// newMethod returns source method, only method name is known without compiled package, cached methods are restored with it
+func newMethod(name string) reflect.Method {
+	return reflect.Method{
+		Name:    name,
 		PkgPath: "",
 		Type:    nil,
 		Func:    reflect.Value{},
 		Index:   0,
 	}
-	return method
 }
 
 // NewType crates a type spec with option
@@ -143,7 +191,14 @@ func NewType(name string, opts ...Option) *Type {
 		strings.Contains(original, "*")) {
 		o.Definition = original
 	}
+	if strings.Contains(o.Type.Package, "/") { //package import path
+		if o.Type.ImportPath == "" {
+			o.Type.ImportPath = o.Type.Package
+		}
+		o.Type.Package = path.Base(o.Type.Package)
+	}
 	o.Type.Imports = o.parseOption.GoImports
+	o.Type.parse = parseFlags{mode: o.parseMode, breakCycles: o.breakCycles, lenient: o.lenient}
 	return &o.Type
 }
 
@@ -1,35 +1,47 @@
 package xreflect
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
+	"path"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type LookupType func(name string, option ...Option) (reflect.Type, error)
 
 type Types struct {
-	mux      sync.RWMutex
-	parent   *Types
-	packages map[string]*Package
-	info     map[reflect.Type]*Type
+	generation   uint64 //first field to keep 64-bit atomic alignment
+	sealed       uint32
+	mux          sync.RWMutex
+	parent       *Types
+	packages     atomic.Value //map[string]*Package copy-on-write snapshot
+	resolved     atomic.Value //*resolvedTypes
+	resolvedMux  sync.Mutex
+	fingerprints atomic.Value //*fingerprintIndex
+	info         map[reflect.Type]*Type
+	cacheDir     string
+	policy       OverridePolicy
+	listeners    listeners
+	scope        string
+	scoped       bool
 }
 
+// PackageNames returns registry package keys, package import path if known, package name otherwise
 func (t *Types) PackageNames() []string {
 	var result []string
-	t.mux.RLock()
-	for k := range t.packages {
+	for k := range t.packageMap() {
 		result = append(result, k)
 	}
-	t.mux.RUnlock()
 	return result
 }
 
 func (t *Types) Has(name string) bool {
 	aType := NewType(name)
-	pkg := t.Package(aType.Package)
+	pkg := t.Package(aType.packageKey())
 	if pkg == nil {
 		return false
 	}
@@ -39,48 +51,66 @@ func (t *Types) Has(name string) bool {
 
 func (t *Types) SetParent(parent *Types) {
 	t.parent = parent
+	atomic.AddUint64(&t.generation, 1)
 }
 
+// Info returns registered type info, anonymous struct falls back to unique structurally equal registered type,
+// then to parent registry info
 func (t *Types) Info(rt reflect.Type) *Type {
 	t.mux.RLock()
 	ret := t.info[rt]
 	t.mux.RUnlock()
+	if ret != nil || rt == nil {
+		return ret
+	}
+	if ret = t.matchInfo(rt); ret == nil && t.parent != nil {
+		ret = t.parent.Info(rt)
+	}
 	return ret
 }
 
-func (t *Types) Package(name string) *Package {
+// exactInfo returns info registered for the exact type, then parent registry info, structurally equal types are not matched
+func (t *Types) exactInfo(rt reflect.Type) *Type {
 	t.mux.RLock()
-	pkg := t.packages[name]
+	ret := t.info[rt]
 	t.mux.RUnlock()
-	return pkg
+	if ret == nil && t.parent != nil {
+		ret = t.parent.exactInfo(rt)
+	}
+	return ret
 }
 
-func (t *Types) MergeFrom(from *Types) error {
-	if from == nil {
-		return nil
-	}
-	packages := from.PackageNames()
-	for _, pkgName := range packages {
-		pkg := from.Package(pkgName)
-		destPkg := t.ensurePackage(pkg.Name, pkg.Path)
-		typeNames := pkg.TypeNames()
-		for _, name := range typeNames {
-			aType, _ := pkg.Lookup(name)
-			if err := destPkg.register(name, aType); err != nil {
-				return err
-			}
-		}
+// Package returns package for supplied import path, unambiguous package name or alias
+func (t *Types) Package(name string) *Package {
+	return t.lookupPackage(name)
+}
+
+// RegisterAlias registers package alias, alias can be used in place of package name in lookups
+func (t *Types) RegisterAlias(alias, importPath string) error {
+	if err := t.checkWritable(); err != nil {
+		return err
 	}
+	t.mux.Lock()
+	t.updatePackages(func(index *packageIndex) {
+		index.aliases[alias] = importPath
+	})
+	t.mux.Unlock()
 	return nil
 }
 
+// MergeFrom merges supplied registry with registry override policy
+func (t *Types) MergeFrom(from *Types) error {
+	_, err := t.Merge(from)
+	return err
+}
+
 func (t *Types) Methods(name string, opts ...Option) ([]reflect.Method, error) {
 	aType := NewType(name, opts...)
 	ret, err := t.lookupMethods(aType)
 	if ret != nil {
 		return ret, nil
 	}
-	if t.parent != nil {
+	if t.parent != nil && !errors.Is(err, ErrAmbiguousPackage) {
 		return t.parent.Methods(name, opts...)
 	}
 	return nil, err
@@ -88,14 +118,25 @@ func (t *Types) Methods(name string, opts ...Option) ([]reflect.Method, error) {
 
 func (t *Types) Symbol(symbol string, opts ...Option) (interface{}, error) {
 	aType := NewType("", opts...)
-	pkg := t.ensurePackage(aType.Package, aType.PackagePath)
-	var err error
-	if pkg.dirType == nil {
-		if pkg.dirType, err = ParseTypes(pkg.Path, WithTypeLookup(t.Lookup)); err != nil {
+	pkg, err := t.ensurePackage(aType.packageKey(), aType.PackagePath)
+	if err != nil {
+		return nil, err
+	}
+	pkg.mux.RLock()
+	value, ok := pkg.constants[symbol]
+	pkg.mux.RUnlock()
+	dirType := pkg.sourceTypes()
+	if ok && dirType == nil {
+		return strings.Trim(value, `"`), nil
+	}
+	if dirType == nil {
+		parsed, err := ParseTypes(pkg.Path, WithTypeLookup(t.Lookup))
+		if err != nil {
 			return nil, err
 		}
+		dirType = pkg.setDirType(parsed)
 	}
-	val, err := pkg.dirType.Value(symbol)
+	val, err := dirType.Value(symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -111,9 +152,20 @@ func (t *Types) Symbol(symbol string, opts ...Option) (interface{}, error) {
 	}
 }
 
+// Lookup returns type for supplied name, lookups by name only are served from lock-free cache once resolved
 func (t *Types) Lookup(name string, opts ...Option) (reflect.Type, error) {
-	aType := NewType(name, opts...)
-	return t.LookupType(aType)
+	if len(opts) > 0 {
+		return t.LookupType(NewType(name, opts...))
+	}
+	if rType := t.cachedType(name); rType != nil {
+		return rType, nil
+	}
+	version := t.version()
+	rType, err := t.LookupType(NewType(name))
+	if err == nil && rType != nil {
+		t.cacheType(name, rType, version)
+	}
+	return rType, err
 }
 
 func (t *Types) LookupType(aType *Type) (reflect.Type, error) {
@@ -123,53 +175,75 @@ func (t *Types) LookupType(aType *Type) (reflect.Type, error) {
 			return ret, nil
 		}
 	}
+	if err != nil {
+		t.notify(&Event{Kind: EventLookupMiss, Package: aType.Package, Name: aType.Name, Err: err})
+	}
 	return ret, err
 }
 
 func (t *Types) lookupMethods(aType *Type) ([]reflect.Method, error) {
-	t.mux.RLock()
-	pkg := t.packages[aType.Package]
-	t.mux.RUnlock()
+	pkg, err := t.resolvePackage(aType.packageKey())
+	if err != nil {
+		return nil, err
+	}
 	if pkg == nil {
 		if !aType.IsLoadable() {
-			return nil, fmt.Errorf("unable locate: %s unknown package: '%s'", aType.Name, aType.Package)
+			return nil, newTypeError(ErrPackageNotFound, aType.Name, aType.Package, aType.PackagePath)
+		}
+		if pkg, err = t.ensurePackage(aType.packageKey(), aType.PackagePath); err != nil {
+			return nil, err
 		}
-		pkg = t.ensurePackage(aType.Package, aType.PackagePath)
 	}
 
 	return pkg.Methods(aType.Name)
 }
 
 func (t *Types) lookupType(aType *Type, imps GoImports) (reflect.Type, error) {
-	t.mux.RLock()
-	pkg := t.packages[aType.Package]
-	t.mux.RUnlock()
+	var pkg *Package
+	if aType.ImportPath == "" && imps != nil { //file imports disambiguate package name
+		if importPath := imps.Lookup(aType.Package); importPath != "" {
+			if pkg = t.packageMap()[importPath]; pkg != nil {
+				aType.ImportPath = importPath
+			}
+		}
+	}
+	if pkg == nil {
+		var err error
+		if pkg, err = t.resolvePackage(aType.packageKey()); err != nil {
+			return nil, err
+		}
+	}
 	if pkg == nil {
 		if !aType.IsLoadable() {
 			if imps != nil {
 				if pkgPath := imps.OwnertPkgPath(aType.Package); pkgPath != "" {
-					pkg = t.packages[pkgPath]
-					aType.PackagePath = imps.Lookup(aType.Package)
+					pkg = t.lookupPackage(pkgPath)
 				}
 			}
 			if pkg == nil {
-				return nil, fmt.Errorf("unable locate: %s unknown package: '%s'", aType.Name, aType.Package)
+				return nil, newTypeError(ErrPackageNotFound, aType.Name, aType.Package, aType.PackagePath)
+			}
+		} else {
+			var err error
+			if pkg, err = t.ensurePackage(aType.packageKey(), aType.PackagePath); err != nil {
+				return nil, err
 			}
 		}
-		pkg = t.ensurePackage(aType.Package, aType.PackagePath)
 	}
 
 	rType, err := pkg.Lookup(aType.Name)
 	if rType == nil && t.parent != nil {
-		if parentPkg := t.parent.packages[aType.Package]; parentPkg != nil {
+		if parentPkg := t.parent.lookupPackage(aType.packageKey()); parentPkg != nil {
 			rType, err = parentPkg.Lookup(aType.Name)
 		}
 	}
 
 	if err != nil && aType.IsLoadable() {
 		rErr := t.registerType(aType)
-		if aType.Package != pkg.Name {
-			pkg = t.ensurePackage(aType.Package, aType.PackagePath)
+		if aType.packageKey() != pkg.key() {
+			if pkg, err = t.ensurePackage(aType.packageKey(), aType.PackagePath); err != nil {
+				return nil, err
+			}
 		}
 		rType, err = pkg.Lookup(aType.Name)
 		if err != nil && rErr != nil {
@@ -180,7 +254,7 @@ func (t *Types) lookupType(aType *Type, imps GoImports) (reflect.Type, error) {
 		keyType := NewType(aType.KeyName, WithRegistry(t))
 		kType, err := t.LookupType(keyType)
 		if err != nil {
-			return nil, fmt.Errorf("invalid map key type: %s %v", keyType, err)
+			return nil, fmt.Errorf("invalid map key type: %s %w", keyType, err)
 		}
 		return reflect.MapOf(kType, rType), nil
 	}
@@ -204,14 +278,19 @@ func (t *Types) RegisterReflectTypes(types []reflect.Type, opts ...Option) error
 }
 
 func (t *Types) registerType(aType *Type) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
 	var err error
-	t.ensurePackage(aType.Package, aType.PackagePath)
+	if _, err = t.ensurePackage(aType.packageKey(), aType.PackagePath); err != nil {
+		return err
+	}
 	if aType.Type == nil {
 		if !aType.IsLoadable() {
 			return fmt.Errorf("failed to register %v reflect.Type was nil", aType.TypeName())
 		}
 		if aType.Type, err = aType.LoadType(t); err != nil {
-			if pkg, ok := t.packages[aType.Package]; ok {
+			if pkg := t.lookupPackage(aType.packageKey()); pkg != nil {
 				if aType.Type, err = pkg.Lookup(aType.Name); err == nil {
 					return nil
 				}
@@ -224,8 +303,8 @@ func (t *Types) registerType(aType *Type) error {
 	prev, ok := t.info[aType.Type]
 	t.mux.RUnlock()
 
-	if t.parent != nil {
-		if candidate, _ := t.parent.Lookup(aType.Name, WithPackage(aType.Package)); candidate != nil {
+	if t.parent != nil && !t.scoped {
+		if candidate, _ := t.parent.Lookup(aType.Name, WithPackage(aType.Package), WithImportPath(aType.ImportPath)); candidate != nil {
 			if candidate.Name() != "" { //use named registed type instead
 				aType.Type = candidate
 			}
@@ -236,57 +315,270 @@ func (t *Types) registerType(aType *Type) error {
 	if ok && prev.Type.Name() != "" && aType.Type.Name() == "" {
 		return nil
 	}
+	pkg, err := t.ensurePackage(aType.packageKey(), aType.PackagePath)
+	if err != nil {
+		return err
+	}
+	replaced, changed, err := pkg.register(aType.Name, aType.Type, t.policy)
+	if err != nil || !changed {
+		return err
+	}
 	t.mux.Lock()
 	if len(t.info) == 0 {
 		t.info = map[reflect.Type]*Type{}
 	}
 	t.info[aType.Type] = aType
 	t.mux.Unlock()
+	t.onReplaced(pkg, aType.Name, replaced, aType.Type)
+	return nil
+}
 
-	return t.packages[aType.Package].register(aType.Name, aType.Type)
+// onReplaced removes stale info of replaced type, bumps registry generation and notifies listeners
+func (t *Types) onReplaced(pkg *Package, name string, replaced, rType reflect.Type) {
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if replaced != nil {
+		t.removeInfo(pkg.Name, rawName(name), replaced)
+	}
+	atomic.AddUint64(&t.generation, 1)
+	t.notifyChange(pkg.Name, rawName(name), replaced, rType)
 }
 
-func (t *Types) ensurePackage(pkg string, path string) *Package {
-	t.mux.RLock()
-	if len(t.packages) == 0 {
-		t.packages = map[string]*Package{}
+func (t *Types) removeInfo(pkgName, name string, rType reflect.Type) {
+	t.mux.Lock()
+	if info, ok := t.info[rType]; ok && info.Package == pkgName && rawName(info.Name) == name {
+		delete(t.info, rType)
 	}
-	ret, ok := t.packages[pkg]
-	t.mux.RUnlock()
-	if ok {
-		if path != "" {
-			if actualPath, ok := ret.packagePaths[path]; ok && actualPath != ret.Name {
-				return t.ensurePackage(actualPath, path)
-			}
+	t.mux.Unlock()
+}
+
+// Generation returns registry generation, incremented whenever registered types change
+func (t *Types) Generation() uint64 {
+	return atomic.LoadUint64(&t.generation)
+}
+
+// Unregister removes a type from the registry
+func (t *Types) Unregister(name string, opts ...Option) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
+	aType := NewType(name, opts...)
+	pkg, err := t.resolvePackage(aType.packageKey())
+	if err != nil {
+		return err
+	}
+	if pkg == nil {
+		return newTypeError(ErrPackageNotFound, aType.Name, aType.Package, aType.PackagePath)
+	}
+	removed, err := pkg.unregister(aType.Name)
+	if err != nil {
+		return err
+	}
+	t.removeInfo(pkg.Name, aType.Name, removed)
+	atomic.AddUint64(&t.generation, 1)
+	t.notify(&Event{Kind: EventUnregister, Package: pkg.Name, Name: rawName(aType.Name), Previous: removed})
+	return nil
+}
+
+// ReplacePackage atomically replaces all package types, readers see either previous or new package types
+func (t *Types) ReplacePackage(name string, types map[string]reflect.Type) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
+	pkg, err := t.ensurePackage(name, "")
+	if err != nil {
+		return err
+	}
+	replacement := make(map[string]reflect.Type, len(types))
+	for typeName, rType := range types {
+		if rType.Kind() == reflect.Ptr {
+			rType = rType.Elem()
+		}
+		replacement[typeName] = rType
+	}
+	pkg.mux.Lock()
+	if pkg.Final {
+		pkg.mux.Unlock()
+		return &ConflictError{Package: pkg.Name, Name: "*", Final: true}
+	}
+	previous := pkg.Types
+	pkg.setTypes(replacement)
+	for typeName := range pkg.methods {
+		if _, ok := replacement[typeName]; !ok {
+			delete(pkg.methods, typeName)
 		}
-		return ret
 	}
+	pkg.mux.Unlock()
 	t.mux.Lock()
+	if t.info == nil {
+		t.info = map[reflect.Type]*Type{}
+	}
+	for typeName, rType := range previous {
+		if info, ok := t.info[rType]; ok && info.Package == pkg.Name && rawName(info.Name) == typeName && replacement[typeName] != rType {
+			delete(t.info, rType)
+		}
+	}
+	for typeName, rType := range replacement {
+		if _, ok := t.info[rType]; !ok {
+			t.info[rType] = &Type{Name: typeName, Package: pkg.Name, PackagePath: pkg.Path, Type: rType, Registry: t}
+		}
+	}
+	t.mux.Unlock()
+	atomic.AddUint64(&t.generation, 1)
+	for typeName, rType := range replacement {
+		if previous[typeName] != rType {
+			t.notifyChange(pkg.Name, typeName, previous[typeName], rType)
+		}
+	}
+	for typeName, rType := range previous {
+		if _, ok := replacement[typeName]; !ok {
+			t.notify(&Event{Kind: EventUnregister, Package: pkg.Name, Name: typeName, Previous: rType})
+		}
+	}
+	return nil
+}
 
-	ret = &Package{Name: pkg, Path: path, Types: map[string]reflect.Type{}, methods: map[string][]reflect.Method{}, packagePaths: map[string]string{}}
-	t.packages[pkg] = ret
+// ensurePackage returns package for supplied location, import path or package name, creates it if needed,
+// ambiguous package name is reported as AmbiguousPackageError, sealed registry package is never created
+func (t *Types) ensurePackage(pkg string, location string) (*Package, error) {
+	if ret, err := t.existingPackage(pkg, location); ret != nil || err != nil {
+		return ret, err
+	}
+	t.mux.Lock()
+	if ret, err := t.existingPackage(pkg, location); ret != nil || err != nil {
+		t.mux.Unlock()
+		return ret, err
+	}
+	if err := t.checkWritable(); err != nil {
+		t.mux.Unlock()
+		return nil, err
+	}
+	importPath := ""
+	if strings.Contains(pkg, "/") {
+		importPath, pkg = pkg, path.Base(pkg)
+	}
+	ret := newPackage(pkg, importPath, location)
+	t.storePackages(ret)
 	t.mux.Unlock()
-	return ret
+	t.notify(&Event{Kind: EventPackageCreated, Package: pkg})
+	return ret, nil
+}
+
+func (t *Types) existingPackage(pkg string, location string) (*Package, error) {
+	if location != "" {
+		if ret := t.locationPackage(location); ret != nil {
+			return ret, nil
+		}
+	}
+	return t.resolvePackage(pkg)
+}
+
+// importPackage returns package keyed by supplied import path, creates it if needed,
+// empty package previously created for the location under different key or name is replaced
+func (t *Types) importPackage(importPath, name, location string) (*Package, error) {
+	key := importPath
+	if key == "" {
+		key = name
+	}
+	isCurrent := func(candidate *Package) bool {
+		return candidate != nil && (candidate.Name == name || len(candidate.typeMap()) > 0)
+	}
+	if ret := t.packageMap()[key]; isCurrent(ret) {
+		return ret, nil
+	}
+	t.mux.Lock()
+	if ret := t.packageMap()[key]; isCurrent(ret) {
+		t.mux.Unlock()
+		return ret, nil
+	}
+	if err := t.checkWritable(); err != nil {
+		t.mux.Unlock()
+		return nil, err
+	}
+	ret := newPackage(name, importPath, location)
+	placeholder := t.locationPackage(location)
+	t.updatePackages(func(index *packageIndex) {
+		if placeholder != nil && len(placeholder.typeMap()) == 0 {
+			delete(index.packages, placeholder.key())
+		}
+		index.packages[key] = ret
+		if location != "" {
+			index.locations[location] = key
+		}
+	})
+	t.mux.Unlock()
+	t.notify(&Event{Kind: EventPackageCreated, Package: name})
+	return ret, nil
 }
 
 type Package struct {
-	mux          sync.RWMutex
-	dirType      *DirTypes
-	Final        bool ///final package type can not be overriden //TODO add checks with error handling
-	Name         string
-	Path         string
-	Types        map[string]reflect.Type
-	methods      map[string][]reflect.Method
-	packagePaths map[string]string
+	mux        sync.RWMutex
+	snapshot   atomic.Value //map[string]reflect.Type copy-on-write snapshot
+	dirType    *DirTypes
+	Final      bool //final package type can not be overridden
+	Name       string
+	ImportPath string
+	Path       string
+	Types      map[string]reflect.Type
+	methods    map[string][]reflect.Method
+	constants  map[string]string
+	imports    map[string][]string
 }
 
-func (p *Package) TypeNames() []string {
+func newPackage(name, importPath, location string) *Package {
+	ret := &Package{Name: name, ImportPath: importPath, Path: location, methods: map[string][]reflect.Method{}}
+	ret.setTypes(map[string]reflect.Type{})
+	return ret
+}
+
+// key returns registry package key
+func (p *Package) key() string {
+	if p.ImportPath != "" {
+		return p.ImportPath
+	}
+	return p.Name
+}
+
+// sourceTypes returns package source types or nil if package has not been parsed
+func (p *Package) sourceTypes() *DirTypes {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.dirType
+}
+
+// setDirType sets package source types unless already set, returns actual package source types
+func (p *Package) setDirType(dirType *DirTypes) *DirTypes {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.dirType == nil {
+		p.dirType = dirType
+	}
+	return p.dirType
+}
+
+// Imports returns package imports, available once package source was loaded
+func (p *Package) Imports() []string {
 	var result []string
 	p.mux.RLock()
-	for k := range p.Types {
+	defer p.mux.RUnlock()
+	if p.dirType != nil {
+		for file := range p.dirType.imports {
+			result = append(result, p.dirType.Imports(file)...)
+		}
+		return result
+	}
+	for _, imports := range p.imports {
+		result = append(result, imports...)
+	}
+	return result
+}
+
+func (p *Package) TypeNames() []string {
+	var result []string
+	for k := range p.typeMap() {
 		result = append(result, k)
 	}
-	p.mux.RUnlock()
 	return result
 }
 
@@ -296,8 +588,10 @@ func (p *Package) Methods(name string) ([]reflect.Method, error) {
 		return nil, err
 	}
 	if rType == nil {
-		return nil, fmt.Errorf("failed to lookup type: %v", name)
+		return nil, newTypeError(ErrTypeNotFound, name, p.Name, p.Path)
 	}
+	p.mux.RLock()
+	defer p.mux.RUnlock()
 	return p.methods[name], nil
 }
 
@@ -305,11 +599,9 @@ func (p *Package) Lookup(name string) (reflect.Type, error) {
 	var isPtr bool
 	defSlice, name := isSlice(name)
 	isPtr, name = isPointer(name)
-	p.mux.RLock()
-	ret, ok := p.Types[name]
-	p.mux.RUnlock()
+	ret, ok := p.typeMap()[name]
 	if !ok {
-		return nil, fmt.Errorf("unable locate : %s in package: %s", name, p.Name)
+		return nil, newTypeError(ErrTypeNotFound, name, p.Name, p.Path)
 	}
 	if ret != nil && isPtr {
 		ret = reflect.PtrTo(ret)
@@ -320,20 +612,50 @@ func (p *Package) Lookup(name string) (reflect.Type, error) {
 	return ret, nil
 }
 
-// register registers a type in the package,
-func (p *Package) register(name string, t reflect.Type) error {
+// register registers a type in the package, name conflicts are handled with supplied policy,
+// returns previous type and flag if registered type has changed
+func (p *Package) register(name string, t reflect.Type, policy OverridePolicy) (reflect.Type, bool, error) {
 	p.mux.Lock()
+	defer p.mux.Unlock()
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 	_, name = isPointer(name)
-	p.Types[name] = t
-	p.mux.Unlock()
-	return nil
+	previous := p.Types[name]
+	t, err := policy.resolve(p, name, previous, t)
+	if err != nil {
+		return nil, false, err
+	}
+	if previous == t {
+		return previous, false, nil
+	}
+	types := p.copyTypes()
+	types[name] = t
+	p.setTypes(types)
+	return previous, true, nil
+}
+
+// unregister removes a type from the package, returns removed type
+func (p *Package) unregister(name string) (reflect.Type, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	_, name = isPointer(name)
+	removed, ok := p.Types[name]
+	if !ok {
+		return nil, newTypeError(ErrTypeNotFound, name, p.Name, p.Path)
+	}
+	if p.Final {
+		return nil, &ConflictError{Package: p.Name, Name: name, Existing: removed, Final: true}
+	}
+	types := p.copyTypes()
+	delete(types, name)
+	p.setTypes(types)
+	delete(p.methods, name)
+	return removed, nil
 }
 
 func NewTypes(opts ...Option) *Types {
-	registry := &Types{packages: map[string]*Package{}, info: map[reflect.Type]*Type{}}
+	registry := &Types{info: map[reflect.Type]*Type{}}
 	o := options{}
 	o.Apply(opts...)
 	if o.Registry != nil {
@@ -342,6 +664,8 @@ func NewTypes(opts ...Option) *Types {
 	if registry.parent == nil {
 		registry.parent = buildInTypes
 	}
+	registry.cacheDir = o.cacheDir
+	registry.policy = o.overridePolicy
 	for _, t := range o.withReflectTypes {
 		name := t.Name()
 		if o.withReflectPackage != "" {
@@ -355,37 +679,38 @@ func NewTypes(opts ...Option) *Types {
 	return registry
 }
 
-var buildInTypes = &Types{
-	packages: map[string]*Package{
-		"": &Package{
-			mux:     sync.RWMutex{},
-			dirType: &DirTypes{},
-			Final:   false,
-			Name:    "",
-			Path:    "",
-			Types: map[string]reflect.Type{
-				"int":          IntType,
-				"uint":         UintType,
-				"int8":         Int8Type,
-				"int16":        Int16Type,
-				"int32":        Int32Type,
-				"int64":        Int64Type,
-				"uint8":        Uint8Type,
-				"uint16":       Uint16Type,
-				"uint32":       Uint32Type,
-				"uint64":       Uint64Type,
-				"float32":      Float32Type,
-				"float64":      Float64Type,
-				"bool":         BoolType,
-				"string":       StringType,
-				"byte":         Uint8Type,
-				"rune":         Int32Type,
-				"interface{}":  InterfaceType,
-				"interface {}": InterfaceType,
-				"any":          InterfaceType,
-			},
-			methods:      map[string][]reflect.Method{},
-			packagePaths: map[string]string{},
+var buildInTypes = newBuildInTypes()
+
+func newBuildInTypes() *Types {
+	ret := &Types{}
+	ret.storePackages(&Package{
+		mux:     sync.RWMutex{},
+		dirType: &DirTypes{},
+		Final:   false,
+		Name:    "",
+		Path:    "",
+		Types: map[string]reflect.Type{
+			"int":          IntType,
+			"uint":         UintType,
+			"int8":         Int8Type,
+			"int16":        Int16Type,
+			"int32":        Int32Type,
+			"int64":        Int64Type,
+			"uint8":        Uint8Type,
+			"uint16":       Uint16Type,
+			"uint32":       Uint32Type,
+			"uint64":       Uint64Type,
+			"float32":      Float32Type,
+			"float64":      Float64Type,
+			"bool":         BoolType,
+			"string":       StringType,
+			"byte":         Uint8Type,
+			"rune":         Int32Type,
+			"interface{}":  InterfaceType,
+			"interface {}": InterfaceType,
+			"any":          InterfaceType,
 		},
-	},
+		methods: map[string][]reflect.Method{},
+	})
+	return ret
 }
@@ -0,0 +1,744 @@
+package xreflect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTypes_LoadPackages(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	testCases := []struct {
+		description string
+		ctx         context.Context
+		locations   []string
+		expectErr   error
+		lookup      map[string]string
+		packages    []string
+	}{
+		{
+			description: "package with in-module dependency",
+			ctx:         context.Background(),
+			locations:   []string{"./internal/testdata/loader/api"},
+			lookup: map[string]string{
+				"api.Response": "struct { Status string; Order *struct { ID int; Name string; Items []*struct { SKU string; Quantity int } }; Orders []struct { ID int; Name string; Items []*struct { SKU string; Quantity int } } }",
+				"model.Item":   "struct { SKU string; Quantity int }",
+			},
+			packages: []string{"github.com/viant/xreflect/internal/testdata/loader/api", "github.com/viant/xreflect/internal/testdata/loader/model"},
+		},
+		{
+			description: "canceled context",
+			ctx:         canceled,
+			locations:   []string{"./internal/testdata/loader/api"},
+			expectErr:   context.Canceled,
+		},
+	}
+
+	for _, testCase := range testCases {
+		types := NewTypes()
+		err := types.LoadPackages(testCase.ctx, testCase.locations, WithConcurrency(2))
+		if testCase.expectErr != nil {
+			assert.Equal(t, testCase.expectErr, err, testCase.description)
+			assert.Empty(t, types.PackageNames(), testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		for name, expected := range testCase.lookup {
+			rType, err := types.Lookup(name)
+			if !assert.Nil(t, err, testCase.description+" "+name) {
+				continue
+			}
+			assert.Equal(t, expected, rType.String(), testCase.description+" "+name)
+		}
+		for _, importPath := range testCase.packages {
+			assert.NotNil(t, types.Package(importPath), testCase.description+" "+importPath)
+		}
+	}
+}
+
+func TestTypes_CacheDir(t *testing.T) {
+	location := t.TempDir()
+	cacheDir := t.TempDir()
+	source, err := os.ReadFile("./internal/testdata/loader/model/order.go")
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Nil(t, os.WriteFile(path.Join(location, "order.go"), source, 0644)) {
+		return
+	}
+	testCases := []struct {
+		description string
+		update      string
+		expectCache bool
+		expect      string
+	}{
+		{
+			description: "cache miss",
+			expect:      "struct { ID int; Name string; Items []*struct { SKU string; Quantity int } }",
+		},
+		{
+			description: "cache hit",
+			expectCache: true,
+			expect:      "struct { ID int; Name string; Items []*struct { SKU string; Quantity int } }",
+		},
+		{
+			description: "changed source",
+			update:      "package model\n\ntype Order struct {\n\tID int\n}\n",
+			expect:      "struct { ID int }",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if testCase.update != "" {
+			if !assert.Nil(t, os.WriteFile(path.Join(location, "order.go"), []byte(testCase.update), 0644), testCase.description) {
+				continue
+			}
+		}
+		types := NewTypes(WithCacheDir(cacheDir))
+		rType, err := types.Lookup("Order", WithPackage("model"), WithPackagePath(location))
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expect, rType.String(), testCase.description)
+		pkg := types.Package("model")
+		if assert.NotNil(t, pkg, testCase.description) {
+			assert.Equal(t, testCase.expectCache, pkg.dirType == nil, testCase.description)
+		}
+	}
+}
+
+func TestTypes_CacheDir_Options(t *testing.T) {
+	location := t.TempDir()
+	cacheDir := t.TempDir()
+	source := "package model\n\ntype Node struct {\n\tID       int\n\tChildren []*Node\n}\n"
+	if !assert.Nil(t, os.WriteFile(path.Join(location, "node.go"), []byte(source), 0644)) {
+		return
+	}
+	testCases := []struct {
+		description string
+		options     []Option
+		expectCache bool
+		expectErr   bool
+	}{
+		{description: "cache miss", options: []Option{WithBreakCycles(true)}},
+		{description: "cache hit", options: []Option{WithBreakCycles(true)}, expectCache: true},
+		{description: "different option", options: []Option{WithBreakCycles(false)}, expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		types := NewTypes(WithCacheDir(cacheDir))
+		options := append([]Option{WithPackage("model"), WithPackagePath(location)}, testCase.options...)
+		rType, err := types.Lookup("Node", options...)
+		if testCase.expectErr {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		field, _ := rType.FieldByName("Children")
+		assert.Equal(t, "Node", field.Tag.Get(TagTypeName), testCase.description)
+		pkg := types.Package("model")
+		if assert.NotNil(t, pkg, testCase.description) {
+			assert.Equal(t, testCase.expectCache, pkg.dirType == nil, testCase.description)
+		}
+	}
+}
+
+func TestTypes_CacheDir_Dependency(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	modelDir, apiDir := path.Join(root, "model"), path.Join(root, "api")
+	files := map[string]string{
+		path.Join(root, "go.mod"):        "module example.com/app\n\ngo 1.16\n",
+		path.Join(modelDir, "order.go"):  "package model\n\ntype Order struct {\n\tID int\n}\n\nfunc (o *Order) Validate() error {\n\treturn nil\n}\n",
+		path.Join(apiDir, "response.go"): "package api\n\nimport \"example.com/app/model\"\n\ntype Response struct {\n\tOrder *model.Order\n}\n",
+	}
+	for location, content := range files {
+		assert.Nil(t, os.MkdirAll(path.Dir(location), 0755))
+		assert.Nil(t, os.WriteFile(location, []byte(content), 0644))
+	}
+	testCases := []struct {
+		description string
+		update      string
+		expectCache bool
+		expect      string
+	}{
+		{description: "cache miss", expect: "struct { Order *struct { ID int } }"},
+		{description: "cache hit", expectCache: true, expect: "struct { Order *struct { ID int } }"},
+		{description: "changed dependency", update: "package model\n\ntype Order struct {\n\tID   int\n\tName string\n}\n", expect: "struct { Order *struct { ID int; Name string } }"},
+	}
+	for _, testCase := range testCases {
+		if testCase.update != "" {
+			assert.Nil(t, os.WriteFile(path.Join(modelDir, "order.go"), []byte(testCase.update), 0644), testCase.description)
+		}
+		types := NewTypes(WithCacheDir(cacheDir))
+		_, err := types.Lookup("Order", WithPackage("model"), WithPackagePath(modelDir))
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		methods, err := types.Methods("Order", WithPackage("model"))
+		if testCase.update == "" && assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, 1, len(methods), testCase.description)
+			assert.Equal(t, "Validate", methods[0].Name, testCase.description)
+		}
+		rType, err := types.Lookup("Response", WithPackage("api"), WithPackagePath(apiDir))
+		if !assert.Nil(t, err, testCase.description) {
+			continue
+		}
+		assert.Equal(t, testCase.expect, rType.String(), testCase.description)
+		if pkg := types.Package("api"); assert.NotNil(t, pkg, testCase.description) {
+			assert.Equal(t, testCase.expectCache, pkg.dirType == nil, testCase.description)
+		}
+	}
+}
+
+func TestTypes_OverridePolicy(t *testing.T) {
+	type Order struct {
+		ID int
+	}
+	orderType := reflect.TypeOf(Order{})
+	anonymousOrder := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	otherType := reflect.StructOf([]reflect.StructField{{Name: "Name", Type: StringType}})
+	testCases := []struct {
+		description string
+		policy      OverridePolicy
+		final       bool
+		first       reflect.Type
+		second      reflect.Type
+		expectErr   bool
+		expect      reflect.Type
+	}{
+		{description: "default replaces", first: orderType, second: otherType, expect: otherType},
+		{description: "error", policy: OverrideError, first: orderType, second: otherType, expectErr: true, expect: orderType},
+		{description: "error on the same type", policy: OverrideError, first: orderType, second: orderType, expect: orderType},
+		{description: "keep first", policy: OverrideKeepFirst, first: orderType, second: otherType, expect: orderType},
+		{description: "replace if equal", policy: OverrideReplaceIfEqual, first: orderType, second: anonymousOrder, expect: anonymousOrder},
+		{description: "replace if equal conflict", policy: OverrideReplaceIfEqual, first: orderType, second: otherType, expectErr: true, expect: orderType},
+		{description: "final package", final: true, first: orderType, second: otherType, expectErr: true, expect: orderType},
+	}
+
+	for _, testCase := range testCases {
+		types := NewTypes(WithOverridePolicy(testCase.policy))
+		if !assert.Nil(t, types.Register("model.Order", WithReflectType(testCase.first)), testCase.description) {
+			continue
+		}
+		types.Package("model").Final = testCase.final
+		err := types.Register("model.Order", WithReflectType(testCase.second))
+		if testCase.expectErr {
+			conflict := &ConflictError{}
+			if assert.True(t, errors.As(err, &conflict), testCase.description) {
+				assert.Equal(t, testCase.first, conflict.Existing, testCase.description)
+				assert.Equal(t, testCase.second, conflict.Candidate, testCase.description)
+			}
+		} else {
+			assert.Nil(t, err, testCase.description)
+		}
+		actual, err := types.Lookup("model.Order")
+		if assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, testCase.expect, actual, testCase.description)
+		}
+	}
+}
+
+func TestTypes_Unregister(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)))
+	generation := types.Generation()
+	assert.NotNil(t, types.Info(orderType))
+
+	assert.Nil(t, types.Unregister("model.Order"))
+	assert.False(t, types.Has("model.Order"))
+	assert.Nil(t, types.Info(orderType))
+	assert.True(t, types.Generation() > generation)
+	assert.True(t, errors.Is(types.Unregister("model.Order"), ErrTypeNotFound))
+	assert.True(t, errors.Is(types.Unregister("unknown.Order"), ErrPackageNotFound))
+}
+
+func TestTypes_ReplacePackage(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	itemType := reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType}})
+	orderV2Type := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Name", Type: StringType}})
+	testCases := []struct {
+		description string
+		final       bool
+		replacement map[string]reflect.Type
+		expectErr   bool
+		expect      map[string]reflect.Type
+	}{
+		{
+			description: "replace package types",
+			replacement: map[string]reflect.Type{"Order": orderV2Type},
+			expect:      map[string]reflect.Type{"Order": orderV2Type},
+		},
+		{
+			description: "final package",
+			final:       true,
+			replacement: map[string]reflect.Type{"Order": orderV2Type},
+			expectErr:   true,
+			expect:      map[string]reflect.Type{"Order": orderType, "Item": itemType},
+		},
+	}
+
+	for _, testCase := range testCases {
+		types := NewTypes()
+		assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)), testCase.description)
+		assert.Nil(t, types.Register("model.Item", WithReflectType(itemType)), testCase.description)
+		types.Package("model").Final = testCase.final
+		generation := types.Generation()
+		err := types.ReplacePackage("model", testCase.replacement)
+		if testCase.expectErr {
+			assert.NotNil(t, err, testCase.description)
+			assert.Equal(t, generation, types.Generation(), testCase.description)
+		} else {
+			assert.Nil(t, err, testCase.description)
+			assert.Equal(t, generation+1, types.Generation(), testCase.description)
+			assert.Nil(t, types.Info(orderType), testCase.description)
+			assert.NotNil(t, types.Info(orderV2Type), testCase.description)
+		}
+		pkg := types.Package("model")
+		assert.Equal(t, len(testCase.expect), len(pkg.TypeNames()), testCase.description)
+		for name, expect := range testCase.expect {
+			actual, err := pkg.Lookup(name)
+			if assert.Nil(t, err, testCase.description) {
+				assert.Equal(t, expect, actual, testCase.description)
+			}
+		}
+	}
+}
+
+func TestTypes_ExportImport(t *testing.T) {
+	type Item struct {
+		SKU      string `json:"sku"`
+		Quantity int
+	}
+	types := NewTypes()
+	assert.Nil(t, types.Register("model.Item", WithReflectType(reflect.TypeOf(Item{}))))
+	assert.Nil(t, types.Register("Order", WithPackage("model"), WithTypeDefinition("struct{ID int; Items []*Item `json:\",omitempty\"`; Created time.Time; Meta map[string]interface{}}")))
+	assert.Nil(t, types.Register("IDs", WithPackage("model"), WithTypeDefinition("[]int")))
+	assert.Nil(t, types.Register("Audit", WithPackage("audit"), WithTypeDefinition("struct{Order *model.Order; Action string}")))
+	pkg := types.Package("model")
+	pkg.methods["Order"] = []reflect.Method{{Name: "Validate"}}
+	pkg.Final = true
+
+	data, err := json.Marshal(types.Export())
+	if !assert.Nil(t, err) {
+		return
+	}
+	document := &Document{}
+	if !assert.Nil(t, json.Unmarshal(data, document)) {
+		return
+	}
+	restored := NewTypes()
+	if !assert.Nil(t, restored.Import(document)) {
+		return
+	}
+	for _, name := range []string{"model.Item", "model.Order", "model.IDs", "audit.Audit"} {
+		expect, err := types.Lookup(name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		actual, err := restored.Lookup(name)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		assert.True(t, structurallyEqual(expect, actual), name)
+	}
+	methods, err := restored.Methods("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, []reflect.Method{{Name: "Validate"}}, methods)
+	assert.True(t, restored.Package("model").Final)
+}
+
+func TestTypes_Subscribe(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	orderV2Type := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Name", Type: StringType}})
+	types := NewTypes()
+	var events []*Event
+	unsubscribe := types.Subscribe(func(event *Event) {
+		events = append(events, event)
+	})
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)))
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)))
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderV2Type)))
+	_, err := types.Lookup("model.Customer")
+	assert.NotNil(t, err)
+	unsubscribe()
+	assert.Nil(t, types.Unregister("model.Order"))
+
+	expect := []*Event{
+		{Kind: EventPackageCreated, Package: "model"},
+		{Kind: EventRegister, Package: "model", Name: "Order", Type: orderType},
+		{Kind: EventReplace, Package: "model", Name: "Order", Type: orderV2Type, Previous: orderType},
+		{Kind: EventLookupMiss, Package: "model", Name: "Customer"},
+	}
+	if !assert.Equal(t, len(expect), len(events)) {
+		return
+	}
+	for i, event := range events {
+		event.Err = nil
+		assert.Equal(t, expect[i], event)
+	}
+}
+
+func TestTypes_Lookup_Snapshot(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	orderV2Type := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Name", Type: StringType}})
+	parent := NewTypes()
+	types := NewTypes(WithRegistry(parent))
+	assert.Nil(t, parent.Register("model.Order", WithReflectType(orderType)))
+
+	actual, err := types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderType, actual)
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = types.Lookup("model.Order")
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	assert.Nil(t, parent.ReplacePackage("model", map[string]reflect.Type{"Order": orderV2Type}))
+	actual, err = types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderV2Type, actual)
+
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)))
+	actual, err = types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderType, actual)
+
+	assert.Nil(t, types.Unregister("model.Order"))
+	actual, err = types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderV2Type, actual)
+}
+
+func TestTypes_Lookup_SetParent(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	orderV2Type := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Name", Type: StringType}})
+	parent := NewTypes()
+	assert.Nil(t, parent.Register("model.Order", WithReflectType(orderType)))
+	assert.Nil(t, parent.Register("model.Audit", WithReflectType(orderType)))
+	other := NewTypes()
+	assert.Nil(t, other.Register("model.Order", WithReflectType(orderV2Type)))
+	types := NewTypes(WithRegistry(parent))
+
+	actual, err := types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderType, actual)
+
+	generation := types.Generation() + parent.Generation()
+	types.SetParent(other)
+	assert.Equal(t, generation, types.Generation()+other.Generation()) //generation sum repeats with replaced parent
+	actual, err = types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderV2Type, actual)
+}
+
+func TestTypes_Lookup_PackageCreated(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("Order", WithPackage("github.com/acme/crm/model"), WithReflectType(orderType)))
+	actual, err := types.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderType, actual)
+
+	generation := types.Generation()
+	_, err = types.ensurePackage("github.com/acme/erp/model", "")
+	assert.Nil(t, err)
+	assert.True(t, types.Generation() > generation)
+	_, err = types.Lookup("model.Order")
+	assert.True(t, errors.Is(err, ErrAmbiguousPackage), err)
+}
+
+func TestTypes_Lookup_Concurrent(t *testing.T) {
+	types := NewTypes()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				rType := reflect.StructOf([]reflect.StructField{{Name: fmt.Sprintf("F%v", j), Type: IntType}})
+				assert.Nil(t, types.Register(fmt.Sprintf("model.T%v", i), WithReflectType(rType)))
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = types.Lookup(fmt.Sprintf("model.T%v", i))
+				_ = types.PackageNames()
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 8; i++ {
+		actual, err := types.Lookup(fmt.Sprintf("model.T%v", i))
+		if assert.Nil(t, err) {
+			assert.Equal(t, "F99", actual.Field(0).Name)
+		}
+	}
+}
+
+func BenchmarkTypes_Lookup(b *testing.B) {
+	types := NewTypes()
+	_ = types.Register("model.Order", WithReflectType(reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = types.Lookup("model.Order")
+	}
+}
+
+func BenchmarkTypes_Lookup_Parallel(b *testing.B) {
+	types := NewTypes()
+	_ = types.Register("model.Order", WithReflectType(reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = types.Lookup("model.Order")
+		}
+	})
+}
+
+func BenchmarkTypes_Lookup_WithOptions(b *testing.B) {
+	types := NewTypes()
+	_ = types.Register("model.Order", WithReflectType(reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = types.Lookup("Order", WithPackage("model"))
+	}
+}
+
+func TestTypes_ImportPath(t *testing.T) {
+	crmOrder := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	erpOrder := reflect.StructOf([]reflect.StructField{{Name: "Code", Type: StringType}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("Order", WithPackage("github.com/acme/crm/model"), WithReflectType(crmOrder)))
+	assert.Nil(t, types.Register("github.com/acme/erp/model.Order", WithReflectType(erpOrder)))
+	assert.Nil(t, types.RegisterAlias("erp", "github.com/acme/erp/model"))
+
+	testCases := []struct {
+		description string
+		name        string
+		opts        []Option
+		expect      reflect.Type
+		expectErr   error
+	}{
+		{description: "crm import path", name: "github.com/acme/crm/model.Order", expect: crmOrder},
+		{description: "erp import path", name: "Order", opts: []Option{WithPackage("github.com/acme/erp/model")}, expect: erpOrder},
+		{description: "alias", name: "erp.Order", expect: erpOrder},
+		{description: "go imports", name: "model.Order", opts: []Option{WithGoImports(GoImports{{Module: "github.com/acme/crm/model"}})}, expect: crmOrder},
+		{description: "ambiguous name", name: "model.Order", expectErr: ErrAmbiguousPackage},
+	}
+	for _, testCase := range testCases {
+		actual, err := types.Lookup(testCase.name, testCase.opts...)
+		if testCase.expectErr != nil {
+			assert.True(t, errors.Is(err, testCase.expectErr), testCase.description)
+			continue
+		}
+		if assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, testCase.expect, actual, testCase.description)
+		}
+	}
+
+	_, err := types.Lookup("model.Order")
+	ambiguous := &AmbiguousPackageError{}
+	if assert.True(t, errors.As(err, &ambiguous)) {
+		assert.Equal(t, []string{"github.com/acme/crm/model", "github.com/acme/erp/model"}, ambiguous.Candidates)
+	}
+	assert.True(t, errors.Is(types.Register("model.Item", WithReflectType(crmOrder)), ErrAmbiguousPackage))
+	assert.True(t, errors.Is(types.ReplacePackage("model", map[string]reflect.Type{"Item": crmOrder}), ErrAmbiguousPackage))
+	_, err = types.Symbol("Version", WithPackage("model"))
+	assert.True(t, errors.Is(err, ErrAmbiguousPackage))
+	_, err = types.Methods("Order", WithPackage("model"), WithPackagePath("/tmp/model"))
+	assert.True(t, errors.Is(err, ErrAmbiguousPackage))
+	assert.Equal(t, 2, len(types.PackageNames()))
+	assert.Equal(t, "model", types.Package("github.com/acme/crm/model").Name)
+	assert.Equal(t, "model", types.Info(erpOrder).Package)
+	assert.Equal(t, "github.com/acme/erp/model", types.Info(erpOrder).ImportPath)
+}
+
+func TestTypes_Scope(t *testing.T) {
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}})
+	itemType := reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType}})
+	tenantOrderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Tenant", Type: StringType}})
+	parent := NewTypes()
+	assert.Nil(t, parent.Register("model.Order", WithReflectType(orderType)))
+	assert.Nil(t, parent.Register("model.Item", WithReflectType(itemType)))
+
+	scope := parent.Scope("tenant")
+	assert.Equal(t, "tenant", scope.ScopeName())
+	assert.Equal(t, parent, scope.Parent())
+	assert.Nil(t, scope.Register("model.Order", WithReflectType(tenantOrderType)))
+
+	testCases := []struct {
+		description  string
+		registry     *Types
+		name         string
+		expect       reflect.Type
+		expectOrigin *Types
+	}{
+		{description: "scope shadows parent", registry: scope, name: "model.Order", expect: tenantOrderType, expectOrigin: scope},
+		{description: "scope falls back to parent", registry: scope, name: "model.Item", expect: itemType, expectOrigin: parent},
+		{description: "parent is isolated", registry: parent, name: "model.Order", expect: orderType, expectOrigin: parent},
+	}
+	for _, testCase := range testCases {
+		actual, err := testCase.registry.Lookup(testCase.name)
+		if assert.Nil(t, err, testCase.description) {
+			assert.Equal(t, testCase.expect, actual, testCase.description)
+		}
+		origin, err := testCase.registry.Origin(testCase.name)
+		if assert.Nil(t, err, testCase.description) {
+			assert.True(t, testCase.expectOrigin == origin, testCase.description)
+		}
+	}
+	_, err := scope.Origin("model.Unknown")
+	assert.True(t, errors.Is(err, ErrTypeNotFound))
+
+	scope.Seal()
+	assert.True(t, scope.IsSealed())
+	assert.True(t, errors.Is(scope.Register("model.Customer", WithReflectType(itemType)), ErrSealed))
+	assert.True(t, errors.Is(scope.Unregister("model.Order"), ErrSealed))
+	assert.True(t, errors.Is(scope.ReplacePackage("model", nil), ErrSealed))
+	packageNames := scope.PackageNames()
+	_, err = scope.Lookup("Order", WithPackage("sealed"), WithPackagePath("internal/testdata/sealed"))
+	assert.NotNil(t, err)
+	_, err = scope.Symbol("Version", WithPackage("sealed"))
+	assert.True(t, errors.Is(err, ErrSealed))
+	assert.Equal(t, packageNames, scope.PackageNames())
+	assert.Nil(t, parent.Register("model.Customer", WithReflectType(itemType)))
+
+	scope.Discard()
+	actual, err := scope.Lookup("model.Order")
+	assert.Nil(t, err)
+	assert.Equal(t, orderType, actual)
+	assert.Empty(t, scope.PackageNames())
+}
+
+type fingerprintItem struct {
+	SKU string `json:"sku"`
+}
+
+func TestTypes_Info_Structural(t *testing.T) {
+	itemType := reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType, Tag: `json:"sku"`}})
+	orderType := reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Items", Type: reflect.SliceOf(itemType)}})
+	types := NewTypes()
+	assert.Nil(t, types.Register("model.Order", WithReflectType(orderType)))
+	assert.Nil(t, types.Register("model.Item", WithReflectType(itemType)))
+
+	testCases := []struct {
+		description string
+		rType       reflect.Type
+		expect      string
+	}{
+		{description: "registered type", rType: orderType, expect: "model.Order"},
+		{description: "structurally equal anonymous type", rType: reflect.StructOf([]reflect.StructField{{Name: "ID", Type: IntType}, {Name: "Items", Type: reflect.SliceOf(reflect.TypeOf(fingerprintItem{}))}}), expect: "model.Order"},
+		{description: "different tag", rType: reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType, Tag: `json:"code"`}})},
+		{description: "named type", rType: reflect.TypeOf(fingerprintItem{})},
+	}
+	for _, testCase := range testCases {
+		info := types.Info(testCase.rType)
+		if testCase.expect == "" {
+			assert.Nil(t, info, testCase.description)
+			continue
+		}
+		if assert.NotNil(t, info, testCase.description) {
+			assert.Equal(t, testCase.expect, info.TypeName(), testCase.description)
+		}
+	}
+
+	scope := types.Scope("tenant")
+	assert.Nil(t, scope.Register("model.Line", WithReflectType(itemType)))
+	assert.Equal(t, 1, len(scope.Matches(itemType)))
+	assert.Equal(t, "model.Line", scope.Info(reflect.StructOf([]reflect.StructField{{Name: "SKU", Type: StringType, Tag: `json:"sku"`}})).TypeName())
+	assert.Equal(t, Fingerprint(itemType), Fingerprint(reflect.TypeOf(fingerprintItem{})))
+}
+
+func TestTypes_Query(t *testing.T) {
+	types := NewTypes()
+	assert.Nil(t, types.LoadPackages(context.Background(), []string{"./internal/testdata/query"}))
+	assert.Nil(t, types.Register("crm.Account", WithReflectType(reflect.StructOf([]reflect.StructField{{Name: "Views", Type: reflect.SliceOf(StringType), Tag: `xdatly:"kind:data_view"`}}))))
+
+	results := types.Query(HasFieldTagValue("xdatly", "kind:data_view"))
+	var actual []string
+	for _, result := range results {
+		actual = append(actual, result.TypeName())
+	}
+	assert.Equal(t, []string{"crm.Account", "query.Customer", "query.Order"}, actual)
+	assert.Equal(t, "github.com/viant/xreflect/internal/testdata/query", results[1].ImportPath)
+	assert.Contains(t, results[2].Definition, "Items")
+	assert.Equal(t, "Orders", results[1].Fields[0].Name)
+	assert.Equal(t, 0, len(types.Query(HasFieldTag("unknown"))))
+}
+
+func TestTypes_Merge(t *testing.T) {
+	const queryPackage = "github.com/viant/xreflect/internal/testdata/query"
+	itemType := reflect.StructOf([]reflect.StructField{{Name: "Code", Type: StringType}})
+	testCases := []struct {
+		description    string
+		policy         OverridePolicy
+		expectErr      error
+		expectReplaced []string
+		expectKept     []string
+		expectItem     reflect.Type
+	}{
+		{description: "replace", policy: OverrideReplace, expectReplaced: []string{"query.Item"}},
+		{description: "keep first", policy: OverrideKeepFirst, expectKept: []string{"query.Item"}, expectItem: itemType},
+		{description: "error", policy: OverrideError, expectErr: ErrTypeConflict, expectItem: itemType},
+	}
+	from := NewTypes()
+	if !assert.Nil(t, from.LoadPackages(context.Background(), []string{"./internal/testdata/query"})) {
+		return
+	}
+	assert.Nil(t, from.RegisterAlias("q", queryPackage))
+	from.Package(queryPackage).Final = true
+
+	for _, testCase := range testCases {
+		types := NewTypes()
+		assert.Nil(t, types.Register("Item", WithImportPath(queryPackage), WithPackage("query"), WithReflectType(itemType)), testCase.description)
+		assert.Nil(t, types.Register("crm.Account", WithReflectType(itemType)), testCase.description)
+		report, err := types.Merge(from, WithOverridePolicy(testCase.policy))
+		if testCase.expectErr != nil {
+			assert.True(t, errors.Is(err, testCase.expectErr), testCase.description)
+			assert.True(t, report.HasConflicts(), testCase.description)
+			assert.False(t, types.Has("query.Order"), testCase.description)
+		} else {
+			if !assert.Nil(t, err, testCase.description) {
+				continue
+			}
+			assert.Equal(t, testCase.expectReplaced, report.Replaced, testCase.description)
+			assert.Equal(t, testCase.expectKept, report.Kept, testCase.description)
+			assert.Contains(t, report.Added, "query.Order", testCase.description)
+			methods, err := types.Methods("q.Order")
+			if assert.Nil(t, err, testCase.description) && assert.Equal(t, 1, len(methods), testCase.description) {
+				assert.Equal(t, "Validate", methods[0].Name, testCase.description)
+			}
+			pkg := types.Package(queryPackage)
+			assert.True(t, pkg.Final, testCase.description)
+			assert.NotNil(t, pkg.sourceTypes(), testCase.description)
+			orderType, _ := types.Lookup("query.Order")
+			if info := types.Info(orderType); assert.NotNil(t, info, testCase.description) {
+				assert.Equal(t, types, info.Registry, testCase.description)
+				assert.Equal(t, queryPackage, info.ImportPath, testCase.description)
+			}
+		}
+		if testCase.expectItem != nil {
+			actual, _ := types.Lookup("query.Item")
+			assert.Equal(t, testCase.expectItem, actual, testCase.description)
+		}
+		assert.True(t, types.Has("crm.Account"), testCase.description)
+	}
+}
@@ -0,0 +1,135 @@
+package xreflect
+
+import (
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validateIdentifier returns error if name is not valid Go identifier
+func validateIdentifier(name, kind string) error {
+	if token.IsIdentifier(name) {
+		return nil
+	}
+	return &GenerateError{Err: ErrInvalidIdentifier, Name: kind + " " + strconv.Quote(name)}
+}
+
+// validateGoType returns error if type can not be expressed in generated code, location describes type or field path
+func validateGoType(rType reflect.Type, location string, opts *options) error {
+	if rType.Kind() == reflect.UnsafePointer {
+		return newUnsupportedTypeError(rType, location)
+	}
+	if rType.Name() != "" {
+		return validateNamedType(rType, location, opts)
+	}
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return validateGoType(rType.Elem(), location, opts)
+	case reflect.Map:
+		if err := validateGoType(rType.Key(), location, opts); err != nil {
+			return err
+		}
+		return validateGoType(rType.Elem(), location, opts)
+	case reflect.Struct:
+		for i := 0; i < rType.NumField(); i++ {
+			aField := rType.Field(i)
+			fieldLocation := location + "." + aField.Name
+			if !token.IsIdentifier(aField.Name) {
+				return &GenerateError{Err: ErrInvalidIdentifier, Name: "field " + fieldLocation}
+			}
+			if err := validateGoType(aField.Type, fieldLocation, opts); err != nil {
+				return err
+			}
+			if err := validateDefault(&aField, fieldLocation, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Interface:
+		if rType.NumMethod() == 0 {
+			return nil
+		}
+	case reflect.Chan, reflect.Func:
+	default:
+		return nil
+	}
+	//chan, func and interface types are written with reflect.Type String without imports
+	if !isPredeclaredType(rType) {
+		return newUnsupportedTypeError(rType, location)
+	}
+	return nil
+}
+
+// validateDefault returns error if generated constructor can not apply field default tag
+func validateDefault(aField *reflect.StructField, location string, opts *options) error {
+	literal, ok := aField.Tag.Lookup(TagDefault)
+	if !opts.constructors || !ok || aField.PkgPath != "" {
+		return nil
+	}
+	if _, err := parseDefault(aField.Type, literal); err != nil {
+		return &GenerateError{Err: ErrInvalidDefault, Name: "field " + location, Cause: err}
+	}
+	return nil
+}
+
+// validateNamedType returns error if named type can not be referenced from generated package
+func validateNamedType(rType reflect.Type, location string, opts *options) error {
+	if strings.ContainsAny(rType.Name(), "[]") { //generic type instance
+		return newUnsupportedTypeError(rType, location)
+	}
+	if rType.PkgPath() == "" || isExported(rType.Name()) {
+		return nil
+	}
+	if isLocalPackage(packageName(rType), rType.PkgPath(), opts.Package, opts.ImportPath) {
+		return nil
+	}
+	return newUnsupportedTypeError(rType, location)
+}
+
+// isPredeclaredType returns true if type is composed of unnamed and predeclared types only
+func isPredeclaredType(rType reflect.Type) bool {
+	if rType.Kind() == reflect.UnsafePointer {
+		return false
+	}
+	if rType.Name() != "" {
+		return rType.PkgPath() == ""
+	}
+	switch rType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		return isPredeclaredType(rType.Elem())
+	case reflect.Map:
+		return isPredeclaredType(rType.Key()) && isPredeclaredType(rType.Elem())
+	case reflect.Func:
+		for i := 0; i < rType.NumIn(); i++ {
+			if !isPredeclaredType(rType.In(i)) {
+				return false
+			}
+		}
+		for i := 0; i < rType.NumOut(); i++ {
+			if !isPredeclaredType(rType.Out(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Interface:
+		for i := 0; i < rType.NumMethod(); i++ {
+			if !isPredeclaredType(rType.Method(i).Type) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < rType.NumField(); i++ {
+			if !isPredeclaredType(rType.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+func newUnsupportedTypeError(rType reflect.Type, location string) error {
+	return &GenerateError{Err: ErrUnsupportedType, Name: location + " (" + rType.String() + ")"}
+}